@@ -0,0 +1,157 @@
+package apierror
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/helderfarias/go-api-kit/endpoint"
+	"github.com/sirupsen/logrus"
+)
+
+// StatusCoder errors carrying the HTTP status
+type StatusCoder interface {
+	StatusCode() int
+}
+
+// Error error carrying a machine readable code, the HTTP status, a message for
+// the client, optional details (e.g. field errors) and the cause for logging
+type Error struct {
+	Code    string
+	Status  int
+	Message string
+	Details interface{}
+	Cause   error
+}
+
+// New constructs an error
+func New(status int, code, message string) *Error {
+	return &Error{Status: status, Code: code, Message: message}
+}
+
+// Wrap constructs an error keeping the cause
+func Wrap(cause error, status int, code, message string) *Error {
+	return &Error{Status: status, Code: code, Message: message, Cause: cause}
+}
+
+// BadRequest 400
+func BadRequest(message string) *Error {
+	return New(http.StatusBadRequest, "bad_request", message)
+}
+
+// Unauthorized 401
+func Unauthorized(message string) *Error {
+	return New(http.StatusUnauthorized, "unauthorized", message)
+}
+
+// Forbidden 403
+func Forbidden(message string) *Error {
+	return New(http.StatusForbidden, "forbidden", message)
+}
+
+// NotFound 404
+func NotFound(message string) *Error {
+	return New(http.StatusNotFound, "not_found", message)
+}
+
+// Conflict 409
+func Conflict(message string) *Error {
+	return New(http.StatusConflict, "conflict", message)
+}
+
+// Validation 422 with the field errors as details
+func Validation(message string, details interface{}) *Error {
+	return New(http.StatusUnprocessableEntity, "validation", message).WithDetails(details)
+}
+
+// Internal 500, the cause is logged but never sent to the client
+func Internal(cause error) *Error {
+	return Wrap(cause, http.StatusInternalServerError, "internal", http.StatusText(http.StatusInternalServerError))
+}
+
+// As finds the first *Error in the chain
+func As(err error) (*Error, bool) {
+	var e *Error
+	if errors.As(err, &e) {
+		return e, true
+	}
+	return nil, false
+}
+
+// From converts any error, errors that aren't *Error become Internal
+func From(err error) *Error {
+	if err == nil {
+		return nil
+	}
+
+	if e, ok := As(err); ok {
+		return e
+	}
+
+	return Internal(err)
+}
+
+// Public the error as sent to clients by the transports: *Error is kept and other errors
+// with a StatusCode() < 500 keep their message. 5xx errors are logged, those that aren't
+// *Error get the status text as message.
+func Public(err error) *Error {
+	if err == nil {
+		return nil
+	}
+
+	e, ok := As(err)
+	if !ok {
+		e = From(err)
+		if sc, ok := err.(StatusCoder); ok && sc.StatusCode() > 0 {
+			e.Status = sc.StatusCode()
+			if e.Status < http.StatusInternalServerError {
+				e.Code, e.Message, e.Cause = "", err.Error(), nil
+			} else {
+				e.Message = http.StatusText(e.Status)
+			}
+		}
+	}
+
+	if e.StatusCode() >= http.StatusInternalServerError {
+		logrus.WithField("apierror.status", e.StatusCode()).Error(err)
+	}
+
+	return e
+}
+
+// WithDetails sets the details
+func (e *Error) WithDetails(details interface{}) *Error {
+	e.Details = details
+	return e
+}
+
+// WithCause sets the cause
+func (e *Error) WithCause(cause error) *Error {
+	e.Cause = cause
+	return e
+}
+
+func (e *Error) Error() string {
+	if e.Cause != nil {
+		return fmt.Sprintf("%v: %v", e.Message, e.Cause)
+	}
+	return e.Message
+}
+
+// Unwrap returns the cause
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+// StatusCode HTTP status, used by the transports
+func (e *Error) StatusCode() int {
+	if e.Status == 0 {
+		return http.StatusInternalServerError
+	}
+	return e.Status
+}
+
+// Response the error as an endpoint response carrying the problem
+func (e *Error) Response() endpoint.EndpointResponse {
+	return endpoint.Response(e.StatusCode(), e.Problem(""))
+}
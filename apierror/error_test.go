@@ -0,0 +1,71 @@
+package apierror
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorKindsAndCause(t *testing.T) {
+	err := fmt.Errorf("find address: %w", NotFound("address not found").WithCause(sql.ErrNoRows))
+
+	e, ok := As(err)
+
+	assert.True(t, ok)
+	assert.Equal(t, 404, e.StatusCode())
+	assert.Equal(t, "not_found", e.Code)
+	assert.True(t, errors.Is(err, sql.ErrNoRows))
+	assert.Equal(t, 500, From(errors.New("boom")).StatusCode())
+}
+
+func TestEncodeProblem(t *testing.T) {
+	rec := httptest.NewRecorder()
+	ctx := ContextWithInstance(context.Background(), "/addresses")
+
+	EncodeProblem(ctx, Validation("invalid address", []string{"street is required"}), rec)
+
+	assert.Equal(t, 422, rec.Code)
+	assert.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{
+		"type": "about:blank",
+		"title": "Unprocessable Entity",
+		"status": 422,
+		"detail": "invalid address",
+		"instance": "/addresses",
+		"code": "validation",
+		"errors": ["street is required"]
+	}`, rec.Body.String())
+}
+
+func TestInternalHidesCause(t *testing.T) {
+	rec := httptest.NewRecorder()
+
+	EncodeProblem(context.Background(), errors.New("pq: connection refused"), rec)
+
+	assert.Equal(t, 500, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "connection refused")
+}
+
+type statusError int
+
+func (e statusError) Error() string   { return fmt.Sprintf("status %d", int(e)) }
+func (e statusError) StatusCode() int { return int(e) }
+
+func TestPublicHidesInternalErrors(t *testing.T) {
+	assert.Equal(t, "address not found", Public(NotFound("address not found")).Message)
+	assert.Equal(t, "status 409", Public(statusError(409)).Message)
+	assert.Equal(t, 409, Public(statusError(409)).StatusCode())
+
+	unavailable := Public(statusError(503))
+	internal := Public(errors.New("pq: connection refused"))
+
+	assert.Equal(t, 503, unavailable.StatusCode())
+	assert.Equal(t, "Service Unavailable", unavailable.Message)
+	assert.Equal(t, 500, internal.StatusCode())
+	assert.Equal(t, "Internal Server Error", internal.Message)
+}
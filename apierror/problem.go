@@ -0,0 +1,67 @@
+package apierror
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+// ProblemContentType media type of RFC 7807 responses
+const ProblemContentType = "application/problem+json"
+
+// TypeBaseURI prefix of the problem type, the error code is appended to it.
+// RFC 7807 uses about:blank when the problem has no additional semantics.
+var TypeBaseURI = ""
+
+// Problem RFC 7807 problem details
+type Problem struct {
+	Type     string      `json:"type"`
+	Title    string      `json:"title"`
+	Status   int         `json:"status"`
+	Detail   string      `json:"detail,omitempty"`
+	Instance string      `json:"instance,omitempty"`
+	Code     string      `json:"code,omitempty"`
+	Errors   interface{} `json:"errors,omitempty"`
+}
+
+// Problem the error as problem details of the instance (usually the request path)
+func (e *Error) Problem(instance string) Problem {
+	typ := "about:blank"
+	if TypeBaseURI != "" && e.Code != "" {
+		typ = TypeBaseURI + e.Code
+	}
+
+	return Problem{
+		Type:     typ,
+		Title:    http.StatusText(e.StatusCode()),
+		Status:   e.StatusCode(),
+		Detail:   e.Message,
+		Instance: instance,
+		Code:     e.Code,
+		Errors:   e.Details,
+	}
+}
+
+// EncodeProblem writes the error as application/problem+json, it has the
+// signature of httptransport.ErrorEncoder
+func EncodeProblem(ctx context.Context, err error, w http.ResponseWriter) {
+	e := Public(err)
+
+	instance := ""
+	if r, ok := ctx.Value(instanceContextKey).(string); ok {
+		instance = r
+	}
+
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(e.StatusCode())
+	json.NewEncoder(w).Encode(e.Problem(instance))
+}
+
+type contextKey string
+
+const instanceContextKey contextKey = "apierror.instance"
+
+// ContextWithInstance stores the problem instance (request path) used by EncodeProblem
+func ContextWithInstance(parent context.Context, instance string) context.Context {
+	return context.WithValue(parent, instanceContextKey, instance)
+}
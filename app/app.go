@@ -0,0 +1,302 @@
+// Package app runs the components of a service, starting them in order and stopping them
+// in reverse order on SIGINT/SIGTERM within a shutdown deadline
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/helderfarias/go-api-kit/health"
+	"github.com/sirupsen/logrus"
+)
+
+// Hook lifecycle of a component, every function is optional
+type Hook struct {
+	Name string
+	// OnStart runs in registration order and must not block, a failure stops the components
+	// already started
+	OnStart func(ctx context.Context) error
+	// Serve blocking loop started after OnStart in its own goroutine (e.g. http.Server.Serve,
+	// mb.Subscriber.Delivery), errors shut the app down
+	Serve func() error
+	// OnStop runs in reverse order, bounded by the shutdown deadline
+	OnStop func(ctx context.Context) error
+}
+
+// Errors failures of the components, in the order they happened
+type Errors []error
+
+// App registry of the component hooks
+type App struct {
+	hooks           []Hook
+	signals         []os.Signal
+	shutdownTimeout time.Duration
+	health          *health.Health
+	drainDelay      time.Duration
+
+	mu       sync.Mutex
+	started  []Hook
+	serving  sync.WaitGroup
+	active   int
+	stopping bool
+	failed   chan error
+	done     chan struct{}
+	once     sync.Once
+}
+
+// Option sets an optional parameter for the app
+type Option func(a *App)
+
+// New constructs an app, shutting down on SIGINT/SIGTERM within 30s by default
+func New(options ...Option) *App {
+	a := &App{
+		signals:         []os.Signal{syscall.SIGINT, syscall.SIGTERM},
+		shutdownTimeout: 30 * time.Second,
+		failed:          make(chan error, 1),
+		done:            make(chan struct{}),
+	}
+
+	for _, option := range options {
+		option(a)
+	}
+
+	return a
+}
+
+// ShutdownTimeout deadline to stop all the components
+func ShutdownTimeout(timeout time.Duration) Option {
+	return func(a *App) { a.shutdownTimeout = timeout }
+}
+
+// Signals signals triggering the shutdown
+func Signals(signals ...os.Signal) Option {
+	return func(a *App) { a.signals = signals }
+}
+
+// Readiness reports the health OUT_OF_SERVICE when the shutdown begins and waits the delay
+// before stopping the components, so load balancers stop routing new requests first
+func Readiness(h *health.Health, delay time.Duration) Option {
+	return func(a *App) {
+		a.health = h
+		a.drainDelay = delay
+	}
+}
+
+// Append registers the hooks, started in the order they are appended
+func (a *App) Append(hooks ...Hook) *App {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.hooks = append(a.hooks, hooks...)
+	return a
+}
+
+// Run starts the components, blocks until a signal, Shutdown or a Serve failure and then
+// stops them, returning the failures as Errors. A signal received while starting cancels
+// the start context and the components already started are stopped.
+func (a *App) Run() error {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, a.signals...)
+	defer signal.Stop(sig)
+
+	startCtx, cancelStart := context.WithCancel(context.Background())
+	defer cancelStart()
+
+	interrupted := make(chan os.Signal, 1)
+	starting := make(chan struct{})
+	go func() {
+		select {
+		case s := <-sig:
+			interrupted <- s
+			cancelStart()
+		case <-starting:
+		}
+	}()
+
+	err := a.Start(startCtx)
+	close(starting)
+	if err != nil {
+		return err
+	}
+
+	errs := Errors{}
+
+	select {
+	case s := <-interrupted:
+		logrus.Infof("app: received %v while starting, shutting down", s)
+	case s := <-sig:
+		logrus.Infof("app: received %v, shutting down", s)
+	case err := <-a.failed:
+		logrus.Errorf("app: %v, shutting down", err)
+		errs = append(errs, err)
+	case <-a.done:
+		logrus.Info("app: shutting down")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), a.shutdownTimeout)
+	defer cancel()
+
+	if err := a.Stop(ctx); err != nil {
+		errs = append(errs, err.(Errors)...)
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// Shutdown makes Run stop the components
+func (a *App) Shutdown() {
+	a.once.Do(func() { close(a.done) })
+}
+
+// Start runs OnStart of each hook and launches its Serve loop, on failure the components
+// already started are stopped
+func (a *App) Start(ctx context.Context) error {
+	a.mu.Lock()
+	hooks := append([]Hook{}, a.hooks...)
+	a.mu.Unlock()
+
+	for _, hook := range hooks {
+		logrus.Infof("app: starting %v", hook.Name)
+
+		if hook.OnStart != nil {
+			if err := hook.OnStart(ctx); err != nil {
+				errs := Errors{fmt.Errorf("%v: start: %v", hook.Name, err)}
+
+				stopCtx, cancel := context.WithTimeout(context.Background(), a.shutdownTimeout)
+				defer cancel()
+
+				if err := a.Stop(stopCtx); err != nil {
+					errs = append(errs, err.(Errors)...)
+				}
+
+				return errs
+			}
+		}
+
+		a.mu.Lock()
+		a.started = append(a.started, hook)
+		a.mu.Unlock()
+
+		if hook.Serve != nil {
+			a.serve(hook)
+		}
+	}
+
+	return nil
+}
+
+// Stop runs OnStop of the started hooks in reverse order and waits the Serve loops, components
+// still running when ctx is done are reported and skipped
+func (a *App) Stop(ctx context.Context) error {
+	a.mu.Lock()
+	a.stopping = true
+	started := a.started
+	a.started = nil
+	a.mu.Unlock()
+
+	if a.health != nil {
+		a.health.SetOutOfService(true)
+
+		select {
+		case <-time.After(a.drainDelay):
+		case <-ctx.Done():
+		}
+	}
+
+	errs := Errors{}
+
+	for i := len(started) - 1; i >= 0; i-- {
+		hook := started[i]
+		if hook.OnStop == nil {
+			continue
+		}
+
+		logrus.Infof("app: stopping %v", hook.Name)
+
+		if err := call(ctx, hook.OnStop); err != nil {
+			errs = append(errs, fmt.Errorf("%v: stop: %v", hook.Name, err))
+		}
+	}
+
+	serving := make(chan struct{})
+	go func() {
+		a.serving.Wait()
+		close(serving)
+	}()
+
+	select {
+	case <-serving:
+	case <-ctx.Done():
+		a.mu.Lock()
+		if a.active > 0 {
+			errs = append(errs, fmt.Errorf("%v components still serving: %v", a.active, ctx.Err()))
+		}
+		a.mu.Unlock()
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+func (e Errors) Error() string {
+	messages := []string{}
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "; ")
+}
+
+func (a *App) serve(hook Hook) {
+	a.mu.Lock()
+	a.active++
+	a.mu.Unlock()
+	a.serving.Add(1)
+
+	go func() {
+		defer a.serving.Done()
+
+		err := hook.Serve()
+
+		a.mu.Lock()
+		a.active--
+		stopping := a.stopping
+		a.mu.Unlock()
+
+		if err != nil && !stopping {
+			select {
+			case a.failed <- fmt.Errorf("%v: %v", hook.Name, err):
+			default:
+			}
+		}
+	}()
+}
+
+// call runs fn giving up when ctx is done, fn keeps running in the background
+func call(ctx context.Context, fn func(ctx context.Context) error) error {
+	done := make(chan error, 1)
+	go func() { done <- fn(ctx) }()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		select {
+		case err := <-done:
+			return err
+		default:
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/helderfarias/go-api-kit/health"
+	"github.com/stretchr/testify/assert"
+)
+
+type recorder struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (r *recorder) hook(name string, startErr error) Hook {
+	return Hook{
+		Name: name,
+		OnStart: func(ctx context.Context) error {
+			r.add("start " + name)
+			return startErr
+		},
+		OnStop: func(ctx context.Context) error {
+			r.add("stop " + name)
+			return nil
+		},
+	}
+}
+
+func (r *recorder) add(call string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, call)
+}
+
+func TestRunStartsInOrderAndStopsInReverse(t *testing.T) {
+	rec := &recorder{}
+	a := New().Append(rec.hook("db", nil), rec.hook("http", nil))
+
+	go a.Shutdown()
+
+	assert.Nil(t, a.Run())
+	assert.Equal(t, []string{"start db", "start http", "stop http", "stop db"}, rec.calls)
+}
+
+func TestStartFailureStopsStartedComponents(t *testing.T) {
+	rec := &recorder{}
+	a := New().Append(rec.hook("db", nil), rec.hook("http", errors.New("address in use")), rec.hook("cron", nil))
+
+	err := a.Run()
+
+	assert.EqualError(t, err, "http: start: address in use")
+	assert.Equal(t, []string{"start db", "start http", "stop db"}, rec.calls)
+}
+
+func TestSignalWhileStartingStopsStartedComponents(t *testing.T) {
+	rec := &recorder{}
+	slow := Hook{
+		Name: "slow",
+		OnStart: func(ctx context.Context) error {
+			syscall.Kill(os.Getpid(), syscall.SIGUSR1)
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	}
+
+	a := New(Signals(syscall.SIGUSR1)).Append(rec.hook("db", nil), slow)
+
+	err := a.Run()
+
+	assert.EqualError(t, err, "slow: start: context canceled")
+	assert.Equal(t, []string{"start db", "stop db"}, rec.calls)
+}
+
+func TestServeFailureShutsDown(t *testing.T) {
+	rec := &recorder{}
+	a := New().Append(rec.hook("db", nil), Hook{
+		Name:  "subscriber",
+		Serve: func() error { return errors.New("connection lost") },
+	})
+
+	err := a.Run()
+
+	assert.EqualError(t, err, "subscriber: connection lost")
+	assert.Equal(t, []string{"start db", "stop db"}, rec.calls)
+}
+
+func TestStopDeadlineAggregatesErrors(t *testing.T) {
+	a := New(ShutdownTimeout(20*time.Millisecond)).Append(Hook{
+		Name: "stuck",
+		OnStop: func(ctx context.Context) error {
+			time.Sleep(time.Second)
+			return nil
+		},
+	}, Hook{
+		Name: "cache",
+		OnStop: func(ctx context.Context) error {
+			return errors.New("already closed")
+		},
+	})
+
+	go a.Shutdown()
+
+	err := a.Run()
+
+	assert.EqualError(t, err, "cache: stop: already closed; stuck: stop: context deadline exceeded")
+	assert.Len(t, err.(Errors), 2)
+}
+
+func TestHTTPServerDrainsInFlightRequests(t *testing.T) {
+	ln, _ := net.Listen("tcp", "127.0.0.1:0")
+	addr := ln.Addr().String()
+	ln.Close()
+
+	inFlight := make(chan struct{})
+	srv := &http.Server{Addr: addr, Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(inFlight)
+		time.Sleep(50 * time.Millisecond)
+		w.Write([]byte("done"))
+	})}
+
+	h := health.New()
+	a := New(Readiness(h, 0)).Append(HTTPServer(srv))
+
+	result := make(chan error)
+	go func() { result <- a.Run() }()
+
+	body := make(chan string)
+	go func() {
+		for i := 0; i < 50; i++ {
+			resp, err := http.Get("http://" + addr)
+			if err == nil {
+				data, _ := ioutil.ReadAll(resp.Body)
+				resp.Body.Close()
+				body <- string(data)
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		body <- ""
+	}()
+
+	<-inFlight
+	a.Shutdown()
+
+	assert.Equal(t, "done", <-body)
+	assert.Nil(t, <-result)
+	assert.Equal(t, health.StatusOutOfService, h.Ready(context.Background()).Status)
+}
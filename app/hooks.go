@@ -0,0 +1,82 @@
+package app
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+
+	"github.com/helderfarias/go-api-kit/cron"
+	"github.com/helderfarias/go-api-kit/mb"
+)
+
+// HTTPServer binds the address on start (so bind errors fail the start), serves in the
+// background and drains the in-flight requests on stop
+func HTTPServer(srv *http.Server) Hook {
+	var listener net.Listener
+
+	return Hook{
+		Name: "http " + srv.Addr,
+		OnStart: func(ctx context.Context) error {
+			addr := srv.Addr
+			if addr == "" {
+				addr = ":http"
+			}
+
+			ln, err := net.Listen("tcp", addr)
+			if err != nil {
+				return err
+			}
+
+			listener = ln
+			return nil
+		},
+		Serve: func() error {
+			if err := srv.Serve(listener); err != http.ErrServerClosed {
+				return err
+			}
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			return srv.Shutdown(ctx)
+		},
+	}
+}
+
+// Subscriber delivers the messages of the receivers until stop closes the subscriber
+func Subscriber(sub mb.Subscriber, receivers ...mb.SubRec) Hook {
+	return Hook{
+		Name: "subscriber",
+		Serve: func() error {
+			return sub.Delivery(receivers...)
+		},
+		OnStop: func(ctx context.Context) error {
+			return sub.Close()
+		},
+	}
+}
+
+// Cron stops the scheduler waiting the running jobs
+func Cron(name string, task *cron.FutureTask) Hook {
+	return Hook{
+		Name: "cron " + name,
+		OnStop: func(ctx context.Context) error {
+			select {
+			case <-task.Stop().Done():
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+	}
+}
+
+// Closer closes the resource on stop, e.g. db.ConnectionFactory or cache.CacheServer
+func Closer(name string, c io.Closer) Hook {
+	return Hook{
+		Name: name,
+		OnStop: func(ctx context.Context) error {
+			return c.Close()
+		},
+	}
+}
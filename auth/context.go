@@ -0,0 +1,40 @@
+package auth
+
+import "context"
+
+type contextKey string
+
+const (
+	tokenContextKey  contextKey = "auth.token"
+	claimsContextKey contextKey = "auth.claims"
+)
+
+// ContextWithToken stores the raw bearer token, transports call it when decoding requests
+func ContextWithToken(parent context.Context, token string) context.Context {
+	return context.WithValue(parent, tokenContextKey, token)
+}
+
+// TokenFromContext raw bearer token stored by the transport
+func TokenFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+
+	token, _ := ctx.Value(tokenContextKey).(string)
+	return token
+}
+
+// ContextWithClaims stores the verified claims
+func ContextWithClaims(parent context.Context, claims *Claims) context.Context {
+	return context.WithValue(parent, claimsContextKey, claims)
+}
+
+// ClaimsFromContext verified claims of the request
+func ClaimsFromContext(ctx context.Context) (*Claims, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+
+	claims, ok := ctx.Value(claimsContextKey).(*Claims)
+	return claims, ok
+}
@@ -0,0 +1,241 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+var (
+	ErrTokenMalformed   = errors.New("token is malformed")
+	ErrTokenSignature   = errors.New("token signature is invalid")
+	ErrTokenExpired     = errors.New("token is expired")
+	ErrTokenNotValidYet = errors.New("token is not valid yet")
+	ErrTokenIssuer      = errors.New("token issuer is invalid")
+	ErrTokenAudience    = errors.New("token audience is invalid")
+	ErrTokenAlgorithm   = errors.New("token algorithm is not allowed")
+)
+
+// Audience aud claim, a single string or a list
+type Audience []string
+
+// Claims registered claims plus the ones used for authorization,
+// every claim of the token is kept in Extra
+type Claims struct {
+	Issuer    string                 `json:"iss,omitempty"`
+	Subject   string                 `json:"sub,omitempty"`
+	Audience  Audience               `json:"aud,omitempty"`
+	ExpiresAt int64                  `json:"exp,omitempty"`
+	NotBefore int64                  `json:"nbf,omitempty"`
+	IssuedAt  int64                  `json:"iat,omitempty"`
+	ID        string                 `json:"jti,omitempty"`
+	Roles     []string               `json:"roles,omitempty"`
+	Scope     string                 `json:"scope,omitempty"`
+	Extra     map[string]interface{} `json:"-"`
+}
+
+// VerifyOptions token validation configurations
+type VerifyOptions struct {
+	// Algorithms allowed, defaults to HS256, RS256 and ES256
+	Algorithms []string
+	Issuer     string
+	// Audience the token must contain one of them
+	Audience []string
+	// Leeway clock skew tolerance for exp and nbf
+	Leeway time.Duration
+	Now    func() time.Time
+}
+
+type header struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	Typ string `json:"typ"`
+}
+
+// Verify checks the signature of the token with the key set and validates its claims
+func Verify(token string, keys KeySet, opts VerifyOptions) (*Claims, error) {
+	if len(opts.Algorithms) == 0 {
+		opts.Algorithms = []string{"HS256", "RS256", "ES256"}
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrTokenMalformed
+	}
+
+	var h header
+	if err := decodeSegment(parts[0], &h); err != nil {
+		return nil, ErrTokenMalformed
+	}
+
+	if !contains(opts.Algorithms, h.Alg) {
+		return nil, ErrTokenAlgorithm
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrTokenMalformed
+	}
+
+	key, err := keys.Key(h.Kid, h.Alg)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := verifySignature(h.Alg, parts[0]+"."+parts[1], signature, key); err != nil {
+		return nil, err
+	}
+
+	claims := &Claims{}
+	if err := decodeSegment(parts[1], claims); err != nil {
+		return nil, ErrTokenMalformed
+	}
+	if err := decodeSegment(parts[1], &claims.Extra); err != nil {
+		return nil, ErrTokenMalformed
+	}
+
+	if err := claims.Valid(opts); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+// Valid validates exp, nbf, iss and aud
+func (c *Claims) Valid(opts VerifyOptions) error {
+	now := time.Now()
+	if opts.Now != nil {
+		now = opts.Now()
+	}
+
+	if c.ExpiresAt != 0 && now.Add(-opts.Leeway).After(time.Unix(c.ExpiresAt, 0)) {
+		return ErrTokenExpired
+	}
+
+	if c.NotBefore != 0 && now.Add(opts.Leeway).Before(time.Unix(c.NotBefore, 0)) {
+		return ErrTokenNotValidYet
+	}
+
+	if opts.Issuer != "" && opts.Issuer != c.Issuer {
+		return ErrTokenIssuer
+	}
+
+	if len(opts.Audience) > 0 {
+		for _, aud := range opts.Audience {
+			if contains(c.Audience, aud) {
+				return nil
+			}
+		}
+		return ErrTokenAudience
+	}
+
+	return nil
+}
+
+// Scopes the scope claim split by spaces
+func (c *Claims) Scopes() []string {
+	return strings.Fields(c.Scope)
+}
+
+// HasRole the roles claim contains the role
+func (c *Claims) HasRole(role string) bool {
+	return contains(c.Roles, role)
+}
+
+// HasScope the scope claim contains the scope
+func (c *Claims) HasScope(scope string) bool {
+	return contains(c.Scopes(), scope)
+}
+
+// UnmarshalJSON accepts a string or a list of strings
+func (a *Audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = Audience{single}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+
+	*a = Audience(list)
+	return nil
+}
+
+func verifySignature(alg, signed string, signature []byte, key interface{}) error {
+	hash := sha256.Sum256([]byte(signed))
+
+	switch alg {
+	case "HS256":
+		secret, ok := key.([]byte)
+		if !ok {
+			return fmt.Errorf("invalid key type %T for %v", key, alg)
+		}
+
+		mac := hmac.New(sha256.New, secret)
+		mac.Write([]byte(signed))
+		if !hmac.Equal(signature, mac.Sum(nil)) {
+			return ErrTokenSignature
+		}
+	case "RS256":
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("invalid key type %T for %v", key, alg)
+		}
+
+		if err := rsa.VerifyPKCS1v15(pub, crypto.SHA256, hash[:], signature); err != nil {
+			return ErrTokenSignature
+		}
+	case "ES256":
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("invalid key type %T for %v", key, alg)
+		}
+
+		if len(signature) != 64 {
+			return ErrTokenSignature
+		}
+
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		if !ecdsa.Verify(pub, hash[:], r, s) {
+			return ErrTokenSignature
+		}
+	default:
+		return ErrTokenAlgorithm
+	}
+
+	return nil
+}
+
+func decodeSegment(segment string, target interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, target)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,142 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func sign(t *testing.T, alg, kid string, claims map[string]interface{}, key interface{}) string {
+	h, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	c, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(h) + "." + base64.RawURLEncoding.EncodeToString(c)
+	hash := sha256.Sum256([]byte(signed))
+
+	var signature []byte
+	switch alg {
+	case "HS256":
+		mac := hmac.New(sha256.New, key.([]byte))
+		mac.Write([]byte(signed))
+		signature = mac.Sum(nil)
+	case "RS256":
+		s, err := rsa.SignPKCS1v15(rand.Reader, key.(*rsa.PrivateKey), crypto.SHA256, hash[:])
+		assert.NoError(t, err)
+		signature = s
+	case "ES256":
+		r, s, err := ecdsa.Sign(rand.Reader, key.(*ecdsa.PrivateKey), hash[:])
+		assert.NoError(t, err)
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestVerifyHS256(t *testing.T) {
+	secret := []byte("secret")
+	token := sign(t, "HS256", "", map[string]interface{}{"sub": "user", "aud": "api", "iss": "kit", "roles": []string{"admin"}, "exp": time.Now().Add(time.Minute).Unix()}, secret)
+
+	claims, err := Verify(token, StaticKey(secret), VerifyOptions{Issuer: "kit", Audience: []string{"api"}})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "user", claims.Subject)
+	assert.Equal(t, []string{"admin"}, claims.Roles)
+	assert.Equal(t, "user", claims.Extra["sub"])
+}
+
+func TestVerifyRejectInvalidClaims(t *testing.T) {
+	secret := []byte("secret")
+
+	expired := sign(t, "HS256", "", map[string]interface{}{"exp": time.Now().Add(-time.Minute).Unix()}, secret)
+	_, err := Verify(expired, StaticKey(secret), VerifyOptions{})
+	assert.Equal(t, ErrTokenExpired, err)
+
+	_, err = Verify(expired, StaticKey(secret), VerifyOptions{Leeway: 2 * time.Minute})
+	assert.NoError(t, err)
+
+	audience := sign(t, "HS256", "", map[string]interface{}{"aud": []string{"other"}}, secret)
+	_, err = Verify(audience, StaticKey(secret), VerifyOptions{Audience: []string{"api"}})
+	assert.Equal(t, ErrTokenAudience, err)
+
+	_, err = Verify(audience, StaticKey([]byte("wrong")), VerifyOptions{})
+	assert.Equal(t, ErrTokenSignature, err)
+
+	_, err = Verify(audience, StaticKey(secret), VerifyOptions{Algorithms: []string{"RS256"}})
+	assert.Equal(t, ErrTokenAlgorithm, err)
+}
+
+func TestVerifyWithJWKSFileAndRotation(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	dir, _ := ioutil.TempDir("", "jwks")
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "jwks.json")
+
+	enc := func(i *big.Int) string { return base64.RawURLEncoding.EncodeToString(i.Bytes()) }
+	write := func(keys ...map[string]string) {
+		body, _ := json.Marshal(map[string]interface{}{"keys": keys})
+		ioutil.WriteFile(file, body, 0644)
+	}
+
+	write(map[string]string{"kid": "rsa-1", "kty": "RSA", "n": enc(rsaKey.N), "e": enc(big.NewInt(int64(rsaKey.E)))})
+
+	jwks := NewJWKSFromFile(file, time.Hour)
+	jwks.minRefresh = 0
+
+	claims, err := Verify(sign(t, "RS256", "rsa-1", map[string]interface{}{"sub": "rsa"}, rsaKey), jwks, VerifyOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "rsa", claims.Subject)
+
+	write(map[string]string{"kid": "ec-1", "kty": "EC", "crv": "P-256", "x": enc(ecKey.X), "y": enc(ecKey.Y)})
+
+	claims, err = Verify(sign(t, "ES256", "ec-1", map[string]interface{}{"sub": "ec"}, ecKey), jwks, VerifyOptions{})
+	assert.NoError(t, err, fmt.Sprint(err))
+	assert.Equal(t, "ec", claims.Subject)
+}
+
+func TestJWKSRefreshIsSharedAndBacksOffOnFailure(t *testing.T) {
+	var loads int32
+	release := make(chan struct{})
+
+	jwks := newJWKS(time.Hour, func() ([]byte, error) {
+		atomic.AddInt32(&loads, 1)
+		<-release
+		return nil, errors.New("jwks host down")
+	})
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			jwks.Key("unknown", "RS256")
+		}()
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	_, err := jwks.Key("unknown", "RS256")
+
+	assert.Equal(t, ErrKeyNotFound, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&loads))
+}
@@ -0,0 +1,281 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/resty.v1"
+)
+
+// ErrKeyNotFound no key for the token kid
+var ErrKeyNotFound = errors.New("key not found")
+
+// KeySet resolves the verification key of a token, HS256 keys are []byte,
+// RS256 *rsa.PublicKey and ES256 *ecdsa.PublicKey
+type KeySet interface {
+	Key(kid, alg string) (interface{}, error)
+}
+
+// JWKS key set loaded from a URL or a file, cached for a TTL and refreshed
+// when a token arrives with an unknown kid (key rotation). Concurrent refreshes
+// share one load and failed loads are retried after minRefresh.
+type JWKS struct {
+	mu          sync.RWMutex
+	refreshMu   sync.Mutex
+	load        func() ([]byte, error)
+	keys        map[string]interface{}
+	fetchedAt   time.Time
+	attemptedAt time.Time
+	lastErr     error
+	ttl         time.Duration
+	minRefresh  time.Duration
+}
+
+// jwksTimeout max time of the JWKS request
+const jwksTimeout = 10 * time.Second
+
+type staticKeys struct {
+	keys map[string]interface{}
+}
+
+type jsonWebKey struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	K   string `json:"k"`
+}
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+// StaticKey single key used for every token
+func StaticKey(key interface{}) KeySet {
+	return &staticKeys{keys: map[string]interface{}{"": key}}
+}
+
+// StaticKeys keys by kid
+func StaticKeys(keys map[string]interface{}) KeySet {
+	return &staticKeys{keys: keys}
+}
+
+// NewJWKSFromURL key set fetched from the URL
+func NewJWKSFromURL(url string, ttl time.Duration) *JWKS {
+	client := resty.New().SetDisableWarn(true).SetTimeout(jwksTimeout)
+
+	return newJWKS(ttl, func() ([]byte, error) {
+		resp, err := client.R().Get(url)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode() != http.StatusOK {
+			return nil, fmt.Errorf("Couldn't load jwks from %v, status: %v", url, resp.StatusCode())
+		}
+
+		return resp.Body(), nil
+	})
+}
+
+// NewJWKSFromFile key set read from the file
+func NewJWKSFromFile(file string, ttl time.Duration) *JWKS {
+	return newJWKS(ttl, func() ([]byte, error) {
+		return ioutil.ReadFile(file)
+	})
+}
+
+func newJWKS(ttl time.Duration, load func() ([]byte, error)) *JWKS {
+	if ttl == 0 {
+		ttl = time.Hour
+	}
+
+	return &JWKS{load: load, ttl: ttl, minRefresh: 30 * time.Second, keys: map[string]interface{}{}}
+}
+
+func (s *staticKeys) Key(kid, alg string) (interface{}, error) {
+	if key, ok := s.keys[kid]; ok {
+		return key, nil
+	}
+
+	if len(s.keys) == 1 {
+		for _, key := range s.keys {
+			return key, nil
+		}
+	}
+
+	return nil, ErrKeyNotFound
+}
+
+// Key returns the key by kid, refreshing the set when it is expired or the kid is unknown
+func (j *JWKS) Key(kid, alg string) (interface{}, error) {
+	j.mu.RLock()
+	key, ok := j.keys[kid]
+	expired := time.Since(j.fetchedAt) > j.ttl
+	attempted := j.attemptedAt
+	j.mu.RUnlock()
+
+	if ok && !expired {
+		return key, nil
+	}
+
+	if time.Since(attempted) < j.minRefresh {
+		if ok {
+			return key, nil
+		}
+		return nil, ErrKeyNotFound
+	}
+
+	if err := j.refreshAfter(attempted); err != nil {
+		logrus.Error(err)
+		if ok {
+			return key, nil
+		}
+		return nil, err
+	}
+
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	if key, ok := j.keys[kid]; ok {
+		return key, nil
+	}
+
+	return nil, ErrKeyNotFound
+}
+
+// Refresh reloads the key set
+func (j *JWKS) Refresh() error {
+	j.refreshMu.Lock()
+	defer j.refreshMu.Unlock()
+
+	return j.refresh()
+}
+
+// refreshAfter reloads the key set unless another caller did it after the attempt
+func (j *JWKS) refreshAfter(attempted time.Time) error {
+	j.refreshMu.Lock()
+	defer j.refreshMu.Unlock()
+
+	j.mu.RLock()
+	last, err := j.attemptedAt, j.lastErr
+	j.mu.RUnlock()
+
+	if last.After(attempted) {
+		return err
+	}
+
+	return j.refresh()
+}
+
+func (j *JWKS) refresh() error {
+	keys, err := j.fetch()
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.attemptedAt = time.Now()
+	j.lastErr = err
+	if err != nil {
+		return err
+	}
+
+	j.keys = keys
+	j.fetchedAt = j.attemptedAt
+	return nil
+}
+
+func (j *JWKS) fetch() (map[string]interface{}, error) {
+	body, err := j.load()
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseJWKS(body)
+}
+
+// ParseJWKS decodes a JSON Web Key Set document into keys by kid
+func ParseJWKS(body []byte) (map[string]interface{}, error) {
+	var set jsonWebKeySet
+	if err := json.Unmarshal(body, &set); err != nil {
+		return nil, err
+	}
+
+	keys := map[string]interface{}{}
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		key, err := jwk.decode()
+		if err != nil {
+			logrus.Warnf("jwks -> kid %v: %v", jwk.Kid, err)
+			continue
+		}
+
+		keys[jwk.Kid] = key
+	}
+
+	return keys, nil
+}
+
+func (k jsonWebKey) decode() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, nil
+	case "oct":
+		return base64.RawURLEncoding.DecodeString(k.K)
+	}
+
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(data), nil
+}
@@ -0,0 +1,344 @@
+// Package binding fills request structs from the HTTP request using tags, e.g.
+//
+//	type ListAddresses struct {
+//		UserID int64     `path:"id"`
+//		Page   int       `query:"page" default:"1"`
+//		Tags   []string  `query:"tag"`
+//		Since  time.Time `query:"since" layout:"2006-01-02"`
+//		Tenant string    `header:"X-Tenant" validate:"required"`
+//		Street string    `json:"street"`
+//	}
+//
+// Fields without path, query, header or form tags are read from the body with the codec of the
+// Content-Type. Slices take repeated values or comma separated lists.
+package binding
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/helderfarias/go-api-kit/apierror"
+	"github.com/helderfarias/go-api-kit/httptransport"
+	"github.com/helderfarias/go-api-kit/router"
+	"github.com/helderfarias/go-api-kit/validation"
+)
+
+// Options binding configurations
+type Options struct {
+	// Validator validates the struct after binding, defaults to validation.Default
+	Validator *validation.Validator
+	// SkipValidation only binds, e.g. when the endpoint uses the Validate middleware
+	SkipValidation bool
+	// Codecs decode the body, defaults to httptransport.DefaultCodecs
+	Codecs httptransport.Codecs
+	// PathParams path params of the request, defaults to the router params
+	PathParams func(r *http.Request) map[string]string
+	// MaxMemory of multipart forms kept in memory, defaults to 32MB
+	MaxMemory int64
+}
+
+// sources of the tagged fields, in the order they are looked up
+var sources = []string{"path", "query", "header", "form"}
+
+// Bind fills v (pointer to struct) from the request, conversion errors of all the fields and
+// the validation errors are returned together as a 422 *apierror.Error with validation.Errors.
+// Unsupported content types are rejected with 415, carrying the field errors as details.
+func Bind(r *http.Request, v interface{}, options ...Options) error {
+	opt := Options{}
+	if len(options) >= 1 {
+		opt = options[0]
+	}
+
+	if opt.Validator == nil {
+		opt.Validator = validation.Default
+	}
+	if opt.Codecs == nil {
+		opt.Codecs = httptransport.DefaultCodecs
+	}
+	if opt.PathParams == nil {
+		opt.PathParams = func(r *http.Request) map[string]string {
+			return router.ParamsFromContext(r.Context())
+		}
+	}
+	if opt.MaxMemory == 0 {
+		opt.MaxMemory = 32 << 20
+	}
+
+	value := reflect.ValueOf(v)
+	if value.Kind() != reflect.Ptr || value.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("binding: %T is not a pointer to struct", v)
+	}
+
+	errs := validation.Errors{}
+
+	var bodyErr *apierror.Error
+	if err := bindBody(r, value.Elem(), opt); err != nil {
+		if e, ok := apierror.As(err); ok {
+			bodyErr = e
+		} else {
+			errs = append(errs, validation.FieldError{Field: "body", Rule: "body", Message: err.Error()})
+		}
+	}
+
+	b := &binder{r: r, path: opt.PathParams(r), errs: &errs}
+	b.bindStruct(value.Elem())
+
+	if bodyErr != nil {
+		if len(errs) > 0 {
+			return bodyErr.WithDetails(errs)
+		}
+		return bodyErr
+	}
+
+	if !opt.SkipValidation && len(errs) == 0 {
+		if err := opt.Validator.Validate(v); err != nil {
+			verrs, ok := err.(validation.Errors)
+			if !ok {
+				return err
+			}
+			errs = append(errs, verrs...)
+		}
+	}
+
+	if len(errs) > 0 {
+		return apierror.Validation("invalid request", errs)
+	}
+
+	return nil
+}
+
+// DecodeRequest decoder binding the value returned by newRequest, to be used as the
+// httptransport.DecodeRequestFunc of the endpoints
+func DecodeRequest(newRequest func() interface{}, options ...Options) httptransport.DecodeRequestFunc {
+	return func(ctx context.Context, r *http.Request) (interface{}, error) {
+		request := newRequest()
+
+		if err := Bind(r, request, options...); err != nil {
+			return nil, err
+		}
+
+		return request, nil
+	}
+}
+
+type binder struct {
+	r    *http.Request
+	path map[string]string
+	errs *validation.Errors
+}
+
+func (b *binder) bindStruct(value reflect.Value) {
+	kind := value.Type()
+
+	for i := 0; i < kind.NumField(); i++ {
+		field := kind.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		fv := value.Field(i)
+
+		source, name := sourceOf(field)
+		if source == "" {
+			if isNested(field) {
+				b.bindStruct(fv)
+				continue
+			}
+
+			if def, ok := field.Tag.Lookup("default"); ok && isZero(fv) {
+				b.set(field, fv, fieldName(field), []string{def})
+			}
+			continue
+		}
+
+		values, ok := b.lookup(source, name)
+		if !ok {
+			def, hasDefault := field.Tag.Lookup("default")
+			if !hasDefault {
+				continue
+			}
+			values = []string{def}
+		}
+
+		b.set(field, fv, name, values)
+	}
+}
+
+func (b *binder) set(field reflect.StructField, fv reflect.Value, name string, values []string) {
+	if err := setValue(fv, values, field.Tag.Get("layout")); err != nil {
+		*b.errs = append(*b.errs, validation.FieldError{
+			Field:   name,
+			Rule:    "type",
+			Param:   typeName(field.Type),
+			Message: fmt.Sprintf("%v must be a valid %v", name, typeName(field.Type)),
+		})
+	}
+}
+
+func (b *binder) lookup(source, name string) ([]string, bool) {
+	switch source {
+	case "path":
+		v, ok := b.path[name]
+		return []string{v}, ok
+	case "query":
+		v, ok := b.r.URL.Query()[name]
+		return v, ok && len(v) > 0
+	case "header":
+		v, ok := b.r.Header[http.CanonicalHeaderKey(name)]
+		return v, ok && len(v) > 0
+	case "form":
+		if b.r.Form == nil {
+			return nil, false
+		}
+		v, ok := b.r.PostForm[name]
+		return v, ok && len(v) > 0
+	}
+
+	return nil, false
+}
+
+// bindBody decodes the body into the fields without source tags, forms are parsed for the form tags.
+// The body is decoded into a copy whose tagged fields are zero and only the other fields are
+// copied back, so the body can't set path, query, header or form values.
+func bindBody(r *http.Request, value reflect.Value, opt Options) error {
+	kind := value.Type()
+
+	if r.Body == nil || r.ContentLength == 0 || r.Method == http.MethodGet || r.Method == http.MethodHead {
+		return nil
+	}
+
+	contentType := r.Header.Get("Content-Type")
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	switch mediaType {
+	case "application/x-www-form-urlencoded":
+		return r.ParseForm()
+	case "multipart/form-data":
+		return r.ParseMultipartForm(opt.MaxMemory)
+	}
+
+	if !hasBodyFields(kind) {
+		return nil
+	}
+
+	codec := opt.Codecs[0]
+	if contentType != "" {
+		c, ok := opt.Codecs.ForContentType(contentType)
+		if !ok {
+			return apierror.New(http.StatusUnsupportedMediaType, "unsupported_media_type", "unsupported content type "+contentType)
+		}
+		codec = c
+	}
+
+	scratch := reflect.New(kind)
+	scratch.Elem().Set(value)
+	clearSourceFields(scratch.Elem())
+
+	if err := codec.Decode(r.Body, scratch.Interface()); err != nil {
+		return fmt.Errorf("invalid body: %v", err)
+	}
+
+	copyBodyFields(value, scratch.Elem())
+	return nil
+}
+
+// clearSourceFields zeroes the fields with source tags
+func clearSourceFields(value reflect.Value) {
+	kind := value.Type()
+
+	for i := 0; i < kind.NumField(); i++ {
+		field := kind.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		if source, _ := sourceOf(field); source != "" {
+			value.Field(i).Set(reflect.Zero(field.Type))
+			continue
+		}
+
+		if isNested(field) {
+			clearSourceFields(value.Field(i))
+		}
+	}
+}
+
+// copyBodyFields copies the fields without source tags
+func copyBodyFields(dst, src reflect.Value) {
+	kind := dst.Type()
+
+	for i := 0; i < kind.NumField(); i++ {
+		field := kind.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		if source, _ := sourceOf(field); source != "" {
+			continue
+		}
+
+		if isNested(field) {
+			copyBodyFields(dst.Field(i), src.Field(i))
+			continue
+		}
+
+		dst.Field(i).Set(src.Field(i))
+	}
+}
+
+func sourceOf(field reflect.StructField) (string, string) {
+	for _, source := range sources {
+		if tag, ok := field.Tag.Lookup(source); ok {
+			name := strings.Split(tag, ",")[0]
+			if name == "-" {
+				return "", ""
+			}
+			if name == "" {
+				name = field.Name
+			}
+			return source, name
+		}
+	}
+	return "", ""
+}
+
+func hasBodyFields(kind reflect.Type) bool {
+	for i := 0; i < kind.NumField(); i++ {
+		field := kind.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		if source, _ := sourceOf(field); source != "" {
+			continue
+		}
+
+		if isNested(field) && !hasBodyFields(indirectType(field.Type)) {
+			continue
+		}
+
+		if strings.Split(field.Tag.Get("json"), ",")[0] == "-" {
+			continue
+		}
+
+		return true
+	}
+	return false
+}
+
+// isNested structs without json tag (usually embedded) holding tagged fields
+func isNested(field reflect.StructField) bool {
+	return field.Type.Kind() == reflect.Struct && field.Type != timeType && field.Tag.Get("json") == ""
+}
+
+func fieldName(field reflect.StructField) string {
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if name == "" || name == "-" {
+		return field.Name
+	}
+	return name
+}
@@ -0,0 +1,145 @@
+package binding
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/helderfarias/go-api-kit/apierror"
+	"github.com/helderfarias/go-api-kit/endpoint"
+	"github.com/helderfarias/go-api-kit/httptransport"
+	"github.com/helderfarias/go-api-kit/router"
+	"github.com/helderfarias/go-api-kit/validation"
+	"github.com/stretchr/testify/assert"
+)
+
+type Paging struct {
+	Page  int `query:"page" default:"1"`
+	Limit int `query:"limit" default:"20" validate:"max=100"`
+}
+
+type updateAddress struct {
+	Paging
+	UserID   int64         `path:"id"`
+	Tags     []string      `query:"tag"`
+	Since    *time.Time    `query:"since" layout:"2006-01-02"`
+	Timeout  time.Duration `query:"timeout"`
+	Tenant   string        `header:"X-Tenant" validate:"required"`
+	Street   string        `json:"street" validate:"required"`
+	Number   int           `json:"number"`
+	Country  string        `json:"country" default:"BR"`
+	internal string
+}
+
+type loginForm struct {
+	User     string `form:"user" validate:"required"`
+	Remember bool   `form:"remember"`
+}
+
+func TestBindFromAllSources(t *testing.T) {
+	r := httptest.NewRequest("PUT", "/users/42/addresses?tag=home&tag=main&since=2020-05-01&timeout=5s&limit=50", strings.NewReader(`{"street":"Main","number":10}`))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("X-Tenant", "acme")
+
+	request := &updateAddress{}
+	err := Bind(r, request, Options{PathParams: func(r *http.Request) map[string]string { return map[string]string{"id": "42"} }})
+
+	since := time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC)
+	assert.Nil(t, err)
+	assert.Equal(t, &updateAddress{
+		Paging:  Paging{Page: 1, Limit: 50},
+		UserID:  42,
+		Tags:    []string{"home", "main"},
+		Since:   &since,
+		Timeout: 5 * time.Second,
+		Tenant:  "acme",
+		Street:  "Main",
+		Number:  10,
+		Country: "BR",
+	}, request)
+}
+
+func TestBindAggregatesConversionAndValidationErrors(t *testing.T) {
+	r := httptest.NewRequest("GET", "/addresses?page=x&since=yesterday", nil)
+
+	err := Bind(r, &updateAddress{}, Options{PathParams: func(r *http.Request) map[string]string { return map[string]string{"id": "abc"} }})
+
+	e, ok := apierror.As(err)
+	assert.True(t, ok)
+	assert.Equal(t, 422, e.StatusCode())
+	assert.Equal(t, validation.Errors{
+		{Field: "page", Rule: "type", Param: "int", Message: "page must be a valid int"},
+		{Field: "id", Rule: "type", Param: "int64", Message: "id must be a valid int64"},
+		{Field: "since", Rule: "type", Param: "time", Message: "since must be a valid time"},
+	}, e.Details)
+
+	err = Bind(httptest.NewRequest("GET", "/addresses?limit=500", nil), &updateAddress{})
+	e, _ = apierror.As(err)
+	assert.Equal(t, []string{"Paging.Limit", "Tenant", "street"}, fields(e.Details.(validation.Errors)))
+}
+
+func TestBindForm(t *testing.T) {
+	r := httptest.NewRequest("POST", "/login", strings.NewReader("user=ana&remember=true"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	request := &loginForm{}
+	assert.Nil(t, Bind(r, request))
+	assert.Equal(t, &loginForm{User: "ana", Remember: true}, request)
+
+	r = httptest.NewRequest("POST", "/login?page=x", strings.NewReader("user=ana"))
+	r.Header.Set("Content-Type", "text/plain")
+	e, _ := apierror.As(Bind(r, &updateAddress{}))
+	assert.Equal(t, 415, e.StatusCode())
+	assert.Equal(t, []string{"page"}, fields(e.Details.(validation.Errors)))
+}
+
+func TestBodyCannotSetTaggedFields(t *testing.T) {
+	r := httptest.NewRequest("PUT", "/users/42/addresses", strings.NewReader(`{"street":"Main","Tenant":"evil","UserID":1,"Page":9}`))
+	r.Header.Set("Content-Type", "application/json")
+
+	request := &updateAddress{UserID: 7}
+	err := Bind(r, request, Options{SkipValidation: true})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "Main", request.Street)
+	assert.Equal(t, "", request.Tenant)
+	assert.Equal(t, int64(7), request.UserID)
+	assert.Equal(t, 1, request.Page)
+}
+
+func TestDecodeRequestWithRouterParams(t *testing.T) {
+	rt := router.NewRouter()
+	rt.Endpoint("PUT", "/users/{id}/addresses",
+		func(ctx context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+			return endpoint.Response(200, request), nil
+		},
+		DecodeRequest(func() interface{} { return &updateAddress{} }),
+		httptransport.EncodeJSONResponse,
+	)
+
+	r := httptest.NewRequest("PUT", "/users/7/addresses", strings.NewReader(`{"street":"Main"}`))
+	r.Header.Set("X-Tenant", "acme")
+
+	rec := httptest.NewRecorder()
+	rt.ServeHTTP(rec, r)
+
+	assert.Equal(t, 200, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"UserID":7`)
+
+	rec = httptest.NewRecorder()
+	rt.ServeHTTP(rec, httptest.NewRequest("PUT", "/users/7/addresses", strings.NewReader(`{}`)))
+
+	assert.Equal(t, 422, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"Tenant is required"`)
+}
+
+func fields(errs validation.Errors) []string {
+	names := []string{}
+	for _, e := range errs {
+		names = append(names, e.Field)
+	}
+	return names
+}
@@ -0,0 +1,141 @@
+package binding
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+// setValue converts the values into the field, slices take every value (or the comma
+// separated list of a single value) and pointers are allocated
+func setValue(field reflect.Value, values []string, layout string) error {
+	if field.Kind() == reflect.Slice && field.Type().Elem().Kind() != reflect.Uint8 {
+		if len(values) == 1 && strings.Contains(values[0], ",") {
+			values = strings.Split(values[0], ",")
+		}
+
+		slice := reflect.MakeSlice(field.Type(), len(values), len(values))
+		for i, v := range values {
+			if err := setScalar(slice.Index(i), strings.TrimSpace(v), layout); err != nil {
+				return err
+			}
+		}
+
+		field.Set(slice)
+		return nil
+	}
+
+	if len(values) == 0 {
+		return nil
+	}
+
+	return setScalar(field, values[0], layout)
+}
+
+func setScalar(field reflect.Value, value, layout string) error {
+	if field.Kind() == reflect.Ptr {
+		if value == "" {
+			return nil
+		}
+
+		ptr := reflect.New(field.Type().Elem())
+		if err := setScalar(ptr.Elem(), value, layout); err != nil {
+			return err
+		}
+
+		field.Set(ptr)
+		return nil
+	}
+
+	switch field.Type() {
+	case timeType:
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		t, err := time.Parse(layout, value)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(t))
+		return nil
+	case durationType:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+
+	if field.CanAddr() {
+		if u, ok := field.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			return u.UnmarshalText([]byte(value))
+		}
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(n)
+	default:
+		return fmt.Errorf("unsupported type %v", field.Type())
+	}
+
+	return nil
+}
+
+func isZero(v reflect.Value) bool {
+	return reflect.DeepEqual(v.Interface(), reflect.Zero(v.Type()).Interface())
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	return t
+}
+
+// typeName name of the type used in the conversion errors, e.g. "int" for []*int
+func typeName(t reflect.Type) string {
+	t = indirectType(t)
+
+	switch t {
+	case timeType:
+		return "time"
+	case durationType:
+		return "duration"
+	}
+
+	return t.Kind().String()
+}
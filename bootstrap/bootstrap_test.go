@@ -0,0 +1,101 @@
+package bootstrap
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/helderfarias/go-api-kit/db"
+	"github.com/helderfarias/go-api-kit/health"
+	"github.com/helderfarias/go-api-kit/mb"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeNATS struct {
+	connected bool
+	closed    bool
+}
+
+func (f *fakeNATS) Pub() mb.Publisher  { return nil }
+func (f *fakeNATS) Sub() mb.Subscriber { return nil }
+func (f *fakeNATS) IsConnected() bool  { return f.connected }
+func (f *fakeNATS) Close()             { f.closed = true }
+
+func TestBuildReportsEveryMissingKey(t *testing.T) {
+	viper.Reset()
+	viper.Set("db_pool_min", 20)
+	viper.Set("cron_report", "every day")
+
+	_, err := New().DB().NATS().Cron("cleanup", func() {}).Cron("report", func() {}).Build()
+
+	configErr, ok := err.(*ConfigError)
+	assert.True(t, ok)
+	assert.Len(t, configErr.Problems, 5)
+	assert.Contains(t, err.Error(), "missing required key db_dsn")
+	assert.Contains(t, err.Error(), "db_pool_min (20) greater than db_pool_max (10)")
+	assert.Contains(t, err.Error(), "missing required key nats_servers")
+	assert.Contains(t, err.Error(), "missing required key cron_cleanup")
+	assert.Contains(t, err.Error(), `invalid expression cron_report="every day"`)
+}
+
+func TestBuildComponents(t *testing.T) {
+	viper.Reset()
+	viper.Set("db_dsn", "postgres://localhost/orders")
+	viper.Set("db_pool_max", 4)
+	viper.Set("nats_servers", "nats://localhost:4222")
+	viper.Set("cron_cleanup", "@every 1h")
+
+	nats := &fakeNATS{connected: true}
+
+	b := New().DB().Cache().NATS().Cron("cleanup", func() {})
+	b.newDB = func(dsn string, poolMin, poolMax int) (db.ConnectionFactory, error) {
+		assert.Equal(t, "postgres://localhost/orders", dsn)
+		assert.Equal(t, DefaultPoolMin, poolMin)
+		assert.Equal(t, 4, poolMax)
+		return nil, nil
+	}
+	b.newNATS = func() mb.Server { return nats }
+
+	c, err := b.Build()
+
+	assert.Nil(t, err)
+	assert.NotNil(t, c.Cache)
+	assert.Equal(t, "cache (memory), nats, cron cleanup", c.String())
+	assert.True(t, c.Crons["cleanup"].Running())
+
+	h := health.New()
+	c.RegisterHealth(h, time.Minute)
+	assert.Equal(t, health.StatusUp, h.Live(nil).Status)
+	assert.Equal(t, health.StatusUp, h.Ready(nil).Status)
+
+	hooks := []string{}
+	for _, hook := range c.Hooks() {
+		hooks = append(hooks, hook.Name)
+	}
+	assert.Equal(t, []string{"cache", "nats", "cron cleanup"}, hooks)
+
+	assert.Nil(t, c.Close())
+	assert.True(t, nats.closed)
+	assert.False(t, c.Crons["cleanup"].Running())
+}
+
+func TestBuildFailureClosesCreatedComponents(t *testing.T) {
+	viper.Reset()
+	viper.Set("nats_servers", "nats://localhost:4222")
+	viper.Set("db_dsn", "postgres://localhost/orders")
+
+	b := New().DB().NATS()
+	b.newDB = func(dsn string, poolMin, poolMax int) (db.ConnectionFactory, error) {
+		return nil, errors.New("connection refused")
+	}
+
+	_, err := b.Build()
+	assert.EqualError(t, err, "bootstrap: db: connection refused")
+
+	b = New().Cache().NATS()
+	b.newNATS = func() mb.Server { return &fakeNATS{} }
+
+	_, err = b.Build()
+	assert.EqualError(t, err, "bootstrap: nats: could not connect to nats://localhost:4222")
+}
@@ -0,0 +1,130 @@
+package bootstrap
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/helderfarias/go-api-kit/app"
+	"github.com/helderfarias/go-api-kit/cache"
+	"github.com/helderfarias/go-api-kit/cron"
+	"github.com/helderfarias/go-api-kit/db"
+	"github.com/helderfarias/go-api-kit/health"
+	"github.com/helderfarias/go-api-kit/mb"
+)
+
+// Container initialized components, the ones not requested are nil
+type Container struct {
+	DB    db.ConnectionFactory
+	Cache cache.CacheServer
+	NATS  mb.Server
+	Crons map[string]*cron.FutureTask
+}
+
+// Hooks stop hooks of the components for the app runner, crons stop first and the DB last
+func (c *Container) Hooks() []app.Hook {
+	hooks := []app.Hook{}
+
+	if c.DB != nil {
+		hooks = append(hooks, app.Closer("db", c.DB))
+	}
+
+	if c.Cache != nil {
+		hooks = append(hooks, app.Closer("cache", c.Cache))
+	}
+
+	if c.NATS != nil {
+		server := c.NATS
+		hooks = append(hooks, app.Hook{
+			Name: "nats",
+			OnStop: func(ctx context.Context) error {
+				server.Close()
+				return nil
+			},
+		})
+	}
+
+	for _, name := range c.cronNames() {
+		hooks = append(hooks, app.Cron(name, c.Crons[name]))
+	}
+
+	return hooks
+}
+
+// RegisterHealth adds the checks of the components, crons take part of the liveness probe
+// and are DOWN when a run is late by more than grace
+func (c *Container) RegisterHealth(h *health.Health, grace time.Duration) {
+	if c.DB != nil {
+		h.Register("db", health.DB(c.DB))
+	}
+
+	if c.Cache != nil {
+		h.Register("cache", health.Cache(c.Cache))
+	}
+
+	if c.NATS != nil {
+		h.Register("nats", health.NATS(c.NATS))
+	}
+
+	for _, name := range c.cronNames() {
+		h.Register("cron_"+name, health.Cron(c.Crons[name], grace), health.CheckOptions{Liveness: true})
+	}
+}
+
+// Close stops the crons and closes the connections
+func (c *Container) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	hooks := c.Hooks()
+
+	errs := app.Errors{}
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if err := hooks[i].OnStop(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("%v: %v", hooks[i].Name, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+func (c *Container) String() string {
+	components := []string{}
+
+	if c.DB != nil {
+		components = append(components, "db")
+	}
+
+	if c.Cache != nil {
+		if _, ok := c.Cache.(cache.Pinger); ok {
+			components = append(components, "cache (redis)")
+		} else {
+			components = append(components, "cache (memory)")
+		}
+	}
+
+	if c.NATS != nil {
+		components = append(components, "nats")
+	}
+
+	for _, name := range c.cronNames() {
+		components = append(components, "cron "+name)
+	}
+
+	return strings.Join(components, ", ")
+}
+
+func (c *Container) cronNames() []string {
+	names := []string{}
+	for name := range c.Crons {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -21,6 +21,16 @@ type CacheServer interface {
 	Close() error
 }
 
+// ScriptRunner implemented by cache servers able to run Lua scripts atomically (Redis)
+type ScriptRunner interface {
+	Eval(script string, keys []string, args ...interface{}) (interface{}, error)
+}
+
+// Pinger implemented by cache servers backed by a remote server (Redis)
+type Pinger interface {
+	Ping() error
+}
+
 func NewCacheServer() CacheServer {
 	return newCacheServer(viper.GetString("cache_redis_servers"))
 }
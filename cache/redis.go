@@ -180,6 +180,27 @@ func (r *redisCache) Get(key string, target interface{}) (interface{}, error) {
 	return target, json.Unmarshal([]byte(cmd.Val()), &target)
 }
 
+func (r *redisCache) Eval(script string, keys []string, args ...interface{}) (interface{}, error) {
+	if r.redis == nil {
+		return nil, errors.New("Redis Master is not configured")
+	}
+
+	cmd := redis.NewScript(script).Run(r.redis, keys, args...)
+	if cmd.Err() != nil {
+		return nil, cmd.Err()
+	}
+
+	return cmd.Val(), nil
+}
+
+func (r *redisCache) Ping() error {
+	if r.redis == nil {
+		return errors.New("Redis Master is not configured")
+	}
+
+	return r.redis.Ping().Err()
+}
+
 func buildTLS(serverName string) *tls.Config {
 	return &tls.Config{
 		ServerName: serverName,
@@ -2,6 +2,8 @@ package cron
 
 import (
 	"context"
+	"sync/atomic"
+	"time"
 
 	"github.com/robfig/cron/v3"
 )
@@ -14,6 +16,8 @@ type Scheduled func()
 
 type FutureTask struct {
 	delegate *cron.Cron
+	lastRun  int64
+	stopped  int32
 }
 
 func NewSchedule(opts ...Options) *Schedule {
@@ -35,6 +39,12 @@ func NewSchedule(opts ...Options) *Schedule {
 	return &Schedule{option: option}
 }
 
+// Validate checks the expression, standard cron fields or descriptors like "@every 1m"
+func Validate(expr string) error {
+	_, err := cron.ParseStandard(expr)
+	return err
+}
+
 func (s *Schedule) Run(task Scheduled) *FutureTask {
 	target := task
 
@@ -42,13 +52,42 @@ func (s *Schedule) Run(task Scheduled) *FutureTask {
 		target = s.option.Recover(target)
 	}
 
+	f := &FutureTask{}
+
 	c := cron.New()
-	c.AddFunc(s.option.Expr, target)
+	c.AddFunc(s.option.Expr, func() {
+		atomic.StoreInt64(&f.lastRun, time.Now().UnixNano())
+		target()
+	})
 	c.Start()
 
-	return &FutureTask{delegate: c}
+	f.delegate = c
+	return f
 }
 
 func (f *FutureTask) Stop() context.Context {
+	atomic.StoreInt32(&f.stopped, 1)
 	return f.delegate.Stop()
 }
+
+// Running false after Stop
+func (f *FutureTask) Running() bool {
+	return atomic.LoadInt32(&f.stopped) == 0
+}
+
+// LastRun when the task last started, zero when it never ran
+func (f *FutureTask) LastRun() time.Time {
+	nanos := atomic.LoadInt64(&f.lastRun)
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos)
+}
+
+// Next the next scheduled run, zero when the expression is invalid
+func (f *FutureTask) Next() time.Time {
+	for _, entry := range f.delegate.Entries() {
+		return entry.Next
+	}
+	return time.Time{}
+}
@@ -0,0 +1,117 @@
+package endpoint
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// ErrInvalidCursor cursor tampered or malformed
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor position in a keyset pagination, the sort keys of the last (or first,
+// when Backward) row returned and the sort they belong to, see PageRequest.NewCursor
+type Cursor struct {
+	Keys     []interface{} `json:"k"`
+	Backward bool          `json:"b,omitempty"`
+	Sort     string        `json:"s,omitempty"`
+}
+
+// CursorCodec encodes cursors as opaque strings signed with HMAC-SHA256,
+// so clients can't forge positions
+type CursorCodec struct {
+	secret []byte
+}
+
+// CursorPaging keyset paging metadata
+type CursorPaging struct {
+	Limit   int64  `json:"limit"`
+	Next    string `json:"next,omitempty"`
+	Prev    string `json:"prev,omitempty"`
+	HasNext bool   `json:"hasNext"`
+	HasPrev bool   `json:"hasPrev"`
+}
+
+// EntityCursorPaging data with keyset paging metadata
+type EntityCursorPaging struct {
+	Data   interface{}  `json:"data"`
+	Paging CursorPaging `json:"paging"`
+}
+
+// NewCursorCodec constructs the codec with the signing secret
+func NewCursorCodec(secret []byte) *CursorCodec {
+	return &CursorCodec{secret: secret}
+}
+
+// PaginateCursor transfer object
+func PaginateCursor(data interface{}, limit int64, next, prev string) interface{} {
+	return EntityCursorPaging{
+		Data: data,
+		Paging: CursorPaging{
+			Limit:   limit,
+			Next:    next,
+			Prev:    prev,
+			HasNext: next != "",
+			HasPrev: prev != "",
+		},
+	}
+}
+
+// Encode the cursor as base64url(json).base64url(hmac)
+func (c *CursorCodec) Encode(cursor Cursor) (string, error) {
+	payload, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+
+	enc := base64.RawURLEncoding.EncodeToString(payload)
+	return enc + "." + base64.RawURLEncoding.EncodeToString(c.sign(enc)), nil
+}
+
+// Decode verifies the signature and decodes the cursor, integer keys are int64
+func (c *CursorCodec) Decode(value string) (*Cursor, error) {
+	parts := strings.Split(value, ".")
+	if len(parts) != 2 {
+		return nil, ErrInvalidCursor
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil || !hmac.Equal(signature, c.sign(parts[0])) {
+		return nil, ErrInvalidCursor
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(payload))
+	dec.UseNumber()
+
+	cursor := &Cursor{}
+	if err := dec.Decode(cursor); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	for i, key := range cursor.Keys {
+		if n, ok := key.(json.Number); ok {
+			if v, err := n.Int64(); err == nil {
+				cursor.Keys[i] = v
+			} else if v, err := n.Float64(); err == nil {
+				cursor.Keys[i] = v
+			}
+		}
+	}
+
+	return cursor, nil
+}
+
+func (c *CursorCodec) sign(payload string) []byte {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
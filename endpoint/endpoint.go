@@ -2,8 +2,13 @@ package endpoint
 
 import (
 	"context"
+	"net/http"
 )
 
+type contextKey string
+
+const requestContextKey contextKey = "endpoint.request"
+
 // Endpoint is the fundamental building block of servers and clients.
 // It represents a single RPC method.
 type Endpoint func(ctx context.Context, request interface{}) (response EndpointResponse, err error)
@@ -14,3 +19,19 @@ func Nop(context.Context, interface{}) (EndpointResponse, error) { return &endpo
 
 // Middleware is a chainable behavior modifier for endpoints.
 type Middleware func(Endpoint) Endpoint
+
+// ContextWithRequest stores the incoming *http.Request, set by the HTTP transport so
+// middlewares can read the method and headers without depending on it
+func ContextWithRequest(ctx context.Context, r *http.Request) context.Context {
+	return context.WithValue(ctx, requestContextKey, r)
+}
+
+// RequestFromContext the *http.Request stored by ContextWithRequest
+func RequestFromContext(ctx context.Context) (*http.Request, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+
+	r, ok := ctx.Value(requestContextKey).(*http.Request)
+	return r, ok
+}
@@ -0,0 +1,206 @@
+package endpoint
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// PageOptions paging limits and allowed sort fields
+type PageOptions struct {
+	DefaultLimit int64
+	MaxLimit     int64
+	// SortFields fields allowed in the sort param, mapped to SQL columns
+	SortFields  map[string]string
+	DefaultSort string
+	// Codec decodes the cursor param, required for keyset pagination
+	Codec *CursorCodec
+}
+
+// SortField column and direction
+type SortField struct {
+	Column string
+	Desc   bool
+}
+
+// PageRequest paging params of the request
+type PageRequest struct {
+	Page   int64
+	Limit  int64
+	Cursor *Cursor
+	Sort   []SortField
+	// SortSpec the sort param normalised, e.g. "-created,id", signed into the cursors
+	SortSpec string
+}
+
+// ParsePageRequest reads page, limit, cursor and sort (e.g. sort=-created_at,id) from the query,
+// limits above MaxLimit are reduced to it and unknown sort fields are rejected
+func ParsePageRequest(query url.Values, opts PageOptions) (PageRequest, error) {
+	if opts.DefaultLimit == 0 {
+		opts.DefaultLimit = 20
+	}
+	if opts.MaxLimit == 0 {
+		opts.MaxLimit = 100
+	}
+
+	p := PageRequest{Page: 1, Limit: opts.DefaultLimit}
+
+	if v := query.Get("page"); v != "" {
+		page, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || page < 1 {
+			return p, fmt.Errorf("invalid page %q", v)
+		}
+		p.Page = page
+	}
+
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || limit < 1 {
+			return p, fmt.Errorf("invalid limit %q", v)
+		}
+		p.Limit = limit
+	}
+
+	if p.Limit > opts.MaxLimit {
+		p.Limit = opts.MaxLimit
+	}
+
+	if v := query.Get("cursor"); v != "" {
+		if opts.Codec == nil {
+			return p, ErrInvalidCursor
+		}
+
+		cursor, err := opts.Codec.Decode(v)
+		if err != nil {
+			return p, err
+		}
+		p.Cursor = cursor
+	}
+
+	sort := query.Get("sort")
+	if sort == "" {
+		sort = opts.DefaultSort
+	}
+
+	spec := []string{}
+	for _, field := range strings.Split(sort, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		desc := strings.HasPrefix(field, "-")
+		name := strings.TrimPrefix(strings.TrimPrefix(field, "-"), "+")
+
+		column, ok := opts.SortFields[name]
+		if !ok {
+			return p, fmt.Errorf("invalid sort field %q", name)
+		}
+
+		p.Sort = append(p.Sort, SortField{Column: column, Desc: desc})
+		if desc {
+			name = "-" + name
+		}
+		spec = append(spec, name)
+	}
+	p.SortSpec = strings.Join(spec, ",")
+
+	// the keys of the cursor only make sense for the sort it was created with
+	if p.Cursor != nil && (p.Cursor.Sort != p.SortSpec || len(p.Cursor.Keys) != len(p.Sort)) {
+		return p, ErrInvalidCursor
+	}
+
+	return p, nil
+}
+
+// NewCursor cursor of the sort keys of a row bound to the sort of the request, e.g. the keys of
+// the last row for the next page or of the first row (backward) for the previous one
+func (p PageRequest) NewCursor(keys []interface{}, backward bool) Cursor {
+	return Cursor{Keys: keys, Backward: backward, Sort: p.SortSpec}
+}
+
+// Offset of the page
+func (p PageRequest) Offset() int64 {
+	return (p.Page - 1) * p.Limit
+}
+
+// OrderBy clause of the sort fields, reversed for backward cursors (the caller
+// reverses the rows back before responding)
+func (p PageRequest) OrderBy() string {
+	if len(p.Sort) == 0 {
+		return ""
+	}
+
+	backward := p.Cursor != nil && p.Cursor.Backward
+
+	columns := []string{}
+	for _, s := range p.Sort {
+		dir := "ASC"
+		if s.Desc != backward {
+			dir = "DESC"
+		}
+		columns = append(columns, s.Column+" "+dir)
+	}
+
+	return "ORDER BY " + strings.Join(columns, ", ")
+}
+
+// PagingLinks RFC 5988 Link header for offset pagination (first, prev, next, last)
+func PagingLinks(u *url.URL, p Paging) string {
+	links := []string{}
+
+	link := func(page int64, rel string) {
+		links = append(links, fmt.Sprintf("<%v>; rel=%q", withQuery(u, map[string]string{
+			"page":  strconv.FormatInt(page, 10),
+			"limit": strconv.FormatInt(p.Limit, 10),
+		}), rel))
+	}
+
+	link(1, "first")
+	if p.HasPrev {
+		link(p.Page-1, "prev")
+	}
+	if p.HasNext {
+		link(p.Page+1, "next")
+	}
+	if p.TotalPages > 0 {
+		link(p.TotalPages, "last")
+	}
+
+	return strings.Join(links, ", ")
+}
+
+// CursorLinks RFC 5988 Link header for cursor pagination (prev, next)
+func CursorLinks(u *url.URL, p CursorPaging) string {
+	links := []string{}
+
+	if p.Prev != "" {
+		links = append(links, fmt.Sprintf("<%v>; rel=\"prev\"", withQuery(u, map[string]string{"cursor": p.Prev, "limit": strconv.FormatInt(p.Limit, 10)})))
+	}
+	if p.Next != "" {
+		links = append(links, fmt.Sprintf("<%v>; rel=\"next\"", withQuery(u, map[string]string{"cursor": p.Next, "limit": strconv.FormatInt(p.Limit, 10)})))
+	}
+
+	return strings.Join(links, ", ")
+}
+
+// WithLinks sets the Link header when there are links
+func WithLinks(resp EndpointResponse, links string) EndpointResponse {
+	if links == "" {
+		return resp
+	}
+	return WithHeader(resp, "Link", links)
+}
+
+func withQuery(u *url.URL, params map[string]string) string {
+	copied := *u
+	query := copied.Query()
+	query.Del("page")
+	query.Del("cursor")
+	for k, v := range params {
+		query.Set(k, v)
+	}
+	copied.RawQuery = query.Encode()
+	return copied.String()
+}
@@ -0,0 +1,53 @@
+package endpoint
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePageRequestWithMaxLimitAndSort(t *testing.T) {
+	query, _ := url.ParseQuery("page=3&limit=500&sort=-created,id")
+
+	p, err := ParsePageRequest(query, PageOptions{MaxLimit: 50, SortFields: map[string]string{"created": "a.created_at", "id": "a.id"}})
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(50), p.Limit)
+	assert.Equal(t, int64(100), p.Offset())
+	assert.Equal(t, "ORDER BY a.created_at DESC, a.id ASC", p.OrderBy())
+
+	_, err = ParsePageRequest(url.Values{"sort": {"password"}}, PageOptions{})
+	assert.EqualError(t, err, `invalid sort field "password"`)
+}
+
+func TestCursorBoundToSort(t *testing.T) {
+	codec := NewCursorCodec([]byte("secret"))
+	opts := PageOptions{Codec: codec, SortFields: map[string]string{"created": "created_at", "id": "id", "name": "name"}}
+
+	first, err := ParsePageRequest(url.Values{"sort": {"-created,+id"}}, opts)
+	assert.Nil(t, err)
+	assert.Equal(t, "-created,id", first.SortSpec)
+
+	cursor, _ := codec.Encode(first.NewCursor([]interface{}{"2020-01-01", 10}, false))
+
+	p, err := ParsePageRequest(url.Values{"cursor": {cursor}, "sort": {"-created,id"}}, opts)
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{"2020-01-01", int64(10)}, p.Cursor.Keys)
+
+	_, err = ParsePageRequest(url.Values{"cursor": {cursor}, "sort": {"name,id"}}, opts)
+	assert.Equal(t, ErrInvalidCursor, err)
+
+	_, err = codec.Decode(cursor[:len(cursor)-2] + "xx")
+	assert.Equal(t, ErrInvalidCursor, err)
+}
+
+func TestPagingLinks(t *testing.T) {
+	u, _ := url.Parse("http://localhost/addresses?q=main&page=2")
+	paging := Paginate(nil, 2, 10, 35).(EntityPaging).Paging
+
+	assert.Equal(t, `<http://localhost/addresses?limit=10&page=1&q=main>; rel="first", `+
+		`<http://localhost/addresses?limit=10&page=1&q=main>; rel="prev", `+
+		`<http://localhost/addresses?limit=10&page=3&q=main>; rel="next", `+
+		`<http://localhost/addresses?limit=10&page=4&q=main>; rel="last"`, PagingLinks(u, paging))
+}
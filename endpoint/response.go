@@ -1,15 +1,28 @@
 package endpoint
 
+import "net/http"
+
 // EndpointResponse the request result.
 type EndpointResponse interface {
 	Code() int
 	Data() interface{}
 }
 
+// MetadataResponse response carrying headers and cookies honored by the transports
+type MetadataResponse interface {
+	EndpointResponse
+
+	Headers() http.Header
+	Cookies() []*http.Cookie
+}
+
 type Paging struct {
-	Page  int64 `json:"page"`
-	Total int64 `json:"total"`
-	Limit int64 `json:"limit"`
+	Page       int64 `json:"page"`
+	Total      int64 `json:"total"`
+	Limit      int64 `json:"limit"`
+	TotalPages int64 `json:"totalPages"`
+	HasNext    bool  `json:"hasNext"`
+	HasPrev    bool  `json:"hasPrev"`
 }
 
 type EntityPaging struct {
@@ -18,8 +31,11 @@ type EntityPaging struct {
 }
 
 type endpointResponse struct {
-	code int
-	data interface{}
+	code    int
+	data    interface{}
+	headers http.Header
+	cookies []*http.Cookie
+	stream  StreamFunc
 }
 
 // Response transfer object
@@ -30,6 +46,60 @@ func Response(code int, data interface{}) EndpointResponse {
 	}
 }
 
+// ResponseWithMetadata transfer object with headers and cookies
+func ResponseWithMetadata(code int, data interface{}, headers http.Header, cookies ...*http.Cookie) MetadataResponse {
+	if headers == nil {
+		headers = http.Header{}
+	}
+
+	return &endpointResponse{
+		code:    code,
+		data:    data,
+		headers: headers,
+		cookies: cookies,
+	}
+}
+
+// Created 201 with the Location header
+func Created(location string, data interface{}) MetadataResponse {
+	return WithHeader(Response(http.StatusCreated, data), "Location", location)
+}
+
+// WithHeader sets the header on a copy of the response, resp is never modified
+func WithHeader(resp EndpointResponse, key, value string) MetadataResponse {
+	m := withMetadata(resp)
+	m.headers.Set(key, value)
+	return m
+}
+
+// WithCookie adds the cookie to a copy of the response, resp is never modified
+func WithCookie(resp EndpointResponse, cookie *http.Cookie) MetadataResponse {
+	m := withMetadata(resp)
+	m.cookies = append(m.cookies, cookie)
+	return m
+}
+
+// WithCacheControl sets the Cache-Control header, e.g. "public, max-age=60"
+func WithCacheControl(resp EndpointResponse, value string) MetadataResponse {
+	return WithHeader(resp, "Cache-Control", value)
+}
+
+// HeadersOf headers of the response, empty when it has no metadata
+func HeadersOf(resp EndpointResponse) http.Header {
+	if m, ok := resp.(MetadataResponse); ok && m.Headers() != nil {
+		return m.Headers()
+	}
+	return http.Header{}
+}
+
+// CookiesOf cookies of the response, empty when it has no metadata
+func CookiesOf(resp EndpointResponse) []*http.Cookie {
+	if m, ok := resp.(MetadataResponse); ok {
+		return m.Cookies()
+	}
+	return nil
+}
+
 // Paginate transfer object
 func Paginate(data interface{}, page, limit, total int64) interface{} {
 	paging := Paging{}
@@ -46,6 +116,13 @@ func Paginate(data interface{}, page, limit, total int64) interface{} {
 		paging.Page = page
 	}
 
+	if limit > 0 {
+		paging.TotalPages = (total + limit - 1) / limit
+	}
+
+	paging.HasNext = page < paging.TotalPages
+	paging.HasPrev = page > 1
+
 	return EntityPaging{Data: data, Paging: paging}
 }
 
@@ -56,3 +133,38 @@ func (e *endpointResponse) Code() int {
 func (e *endpointResponse) Data() interface{} {
 	return e.data
 }
+
+// Headers never stores the empty header, responses are shared by concurrent readers
+func (e *endpointResponse) Headers() http.Header {
+	if e.headers == nil {
+		return http.Header{}
+	}
+	return e.headers
+}
+
+func (e *endpointResponse) Cookies() []*http.Cookie {
+	return e.cookies
+}
+
+func (e *endpointResponse) Stream() StreamFunc {
+	return e.stream
+}
+
+// withMetadata copies the response, so shared or cached responses are never modified
+func withMetadata(resp EndpointResponse) *endpointResponse {
+	m := &endpointResponse{headers: http.Header{}}
+	if resp == nil {
+		return m
+	}
+
+	m.code = resp.Code()
+	m.data = resp.Data()
+	m.stream, _ = StreamOf(resp)
+
+	for k, v := range HeadersOf(resp) {
+		m.headers[k] = append([]string{}, v...)
+	}
+	m.cookies = append(m.cookies, CookiesOf(resp)...)
+
+	return m
+}
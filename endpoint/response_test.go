@@ -1,6 +1,7 @@
 package endpoint
 
 import (
+	"net/http"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -17,9 +18,49 @@ func TestResponseWithPagination(t *testing.T) {
 	assert.Equal(t, EntityPaging{
 		Data: data,
 		Paging: Paging{
-			Page:  1,
-			Total: 20,
-			Limit: 10,
+			Page:       1,
+			Total:      20,
+			Limit:      10,
+			TotalPages: 2,
+			HasNext:    true,
 		},
 	}, resp.Data())
 }
+
+func TestResponseWithMetadata(t *testing.T) {
+	resp := WithCacheControl(Created("/addresses/1", "address"), "no-cache")
+	resp = WithCookie(resp, &http.Cookie{Name: "session", Value: "1"})
+
+	assert.Equal(t, 201, resp.Code())
+	assert.Equal(t, "/addresses/1", resp.Headers().Get("Location"))
+	assert.Equal(t, "no-cache", HeadersOf(resp).Get("Cache-Control"))
+	assert.Equal(t, "session", CookiesOf(resp)[0].Name)
+}
+
+func TestWithHeaderKeepsTheOriginalResponse(t *testing.T) {
+	shared := ResponseWithMetadata(200, "address", http.Header{"Cache-Control": []string{"max-age=60"}})
+
+	resp := WithCookie(WithHeader(shared, "ETag", `"1"`), &http.Cookie{Name: "session", Value: "1"})
+
+	assert.Equal(t, `"1"`, resp.Headers().Get("ETag"))
+	assert.Equal(t, "max-age=60", resp.Headers().Get("Cache-Control"))
+	assert.Equal(t, "", shared.Headers().Get("ETag"))
+	assert.Empty(t, shared.Cookies())
+}
+
+func TestHeadersOfSharedResponseAreReadConcurrently(t *testing.T) {
+	shared := Response(200, "address")
+
+	done := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		go func() {
+			defer func() { done <- struct{}{} }()
+			HeadersOf(shared).Get("ETag")
+		}()
+	}
+	for i := 0; i < 4; i++ {
+		<-done
+	}
+
+	assert.Nil(t, shared.(*endpointResponse).headers)
+}
@@ -0,0 +1,60 @@
+package endpoint
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Event item of a streaming response, ID, Name and Retry are used only by Server-Sent Events
+type Event struct {
+	ID    string
+	Name  string
+	Data  interface{}
+	Retry time.Duration
+}
+
+// StreamFunc produces the events of a streaming response calling send, it must return when
+// ctx is done (client disconnected) or send fails. lastEventID is the id the client received
+// last when resuming an event stream, empty otherwise.
+type StreamFunc func(ctx context.Context, lastEventID string, send func(Event) error) error
+
+// StreamResponse response carrying a stream, wrappers of responses must forward it
+type StreamResponse interface {
+	EndpointResponse
+
+	Stream() StreamFunc
+}
+
+// Stream streaming response produced by the function, Data is nil so middlewares
+// like Cacheable and Conditional leave it alone
+func Stream(code int, stream StreamFunc) MetadataResponse {
+	return &endpointResponse{code: code, headers: http.Header{}, stream: stream}
+}
+
+// StreamChannel streaming response sending the events received from the channel until it is closed
+func StreamChannel(code int, events <-chan Event) MetadataResponse {
+	return Stream(code, func(ctx context.Context, lastEventID string, send func(Event) error) error {
+		for {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case event, ok := <-events:
+				if !ok {
+					return nil
+				}
+				if err := send(event); err != nil {
+					return err
+				}
+			}
+		}
+	})
+}
+
+// StreamOf the stream of the response, false for regular responses
+func StreamOf(resp EndpointResponse) (StreamFunc, bool) {
+	if r, ok := resp.(StreamResponse); ok && r.Stream() != nil {
+		return r.Stream(), true
+	}
+	return nil, false
+}
@@ -1,10 +1,11 @@
 module github.com/helderfarias/go-api-kit
 
-go 1.12
+go 1.13
 
 require (
 	github.com/golang/protobuf v1.4.3 // indirect
 	github.com/google/go-cmp v0.5.4 // indirect
+	github.com/gorilla/websocket v1.4.2
 	github.com/helderfarias/sqlx-wrapper v1.1.1
 	github.com/jmoiron/sqlx v1.2.0
 	github.com/lib/pq v1.0.0
@@ -17,7 +18,9 @@ require (
 	github.com/sirupsen/logrus v1.4.2
 	github.com/spf13/viper v1.6.2
 	github.com/stretchr/testify v1.6.1
-	google.golang.org/protobuf v1.25.0 // indirect
+	github.com/vmihailenco/msgpack/v4 v4.3.12
+	google.golang.org/grpc v1.36.0
+	google.golang.org/protobuf v1.25.0
 	gopkg.in/check.v1 v1.0.0-20190902080502-41f04d3bba15 // indirect
 	gopkg.in/h2non/gock.v1 v1.0.15
 	gopkg.in/redis.v5 v5.2.9
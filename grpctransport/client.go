@@ -0,0 +1,143 @@
+package grpctransport
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/helderfarias/go-api-kit/auth"
+	"github.com/helderfarias/go-api-kit/endpoint"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// EncodeRequestFunc converts the endpoint request into the gRPC request message
+type EncodeRequestFunc func(ctx context.Context, request interface{}) (interface{}, error)
+
+// DecodeResponseFunc converts the gRPC reply message into an endpoint response
+type DecodeResponseFunc func(ctx context.Context, reply interface{}) (endpoint.EndpointResponse, error)
+
+// ClientRequestFunc runs before the call, may add outgoing metadata
+type ClientRequestFunc func(ctx context.Context, md *metadata.MD) context.Context
+
+// ClientResponseFunc runs after the call with the header and trailer metadata received
+type ClientResponseFunc func(ctx context.Context, header metadata.MD, trailer metadata.MD) context.Context
+
+// Client wraps a remote gRPC method as an endpoint
+type Client struct {
+	conn   *grpc.ClientConn
+	method string
+	enc    EncodeRequestFunc
+	dec    DecodeResponseFunc
+	reply  reflect.Type
+	before []ClientRequestFunc
+	after  []ClientResponseFunc
+	opts   []grpc.CallOption
+}
+
+// ClientOption sets an optional parameter for clients
+type ClientOption func(c *Client)
+
+// NewClient constructs a client for the method of the service (e.g. "pkg.Addresses", "Find"),
+// reply is a value of the reply message type, e.g. pb.Address{}
+func NewClient(conn *grpc.ClientConn, serviceName, method string, enc EncodeRequestFunc, dec DecodeResponseFunc, reply interface{}, options ...ClientOption) *Client {
+	c := &Client{
+		conn:   conn,
+		method: fmt.Sprintf("/%s/%s", serviceName, method),
+		enc:    enc,
+		dec:    dec,
+		reply:  reflect.Indirect(reflect.ValueOf(reply)).Type(),
+	}
+
+	for _, o := range options {
+		o(c)
+	}
+
+	return c
+}
+
+// ClientBefore functions executed on the outgoing metadata before the call
+func ClientBefore(before ...ClientRequestFunc) ClientOption {
+	return func(c *Client) {
+		c.before = append(c.before, before...)
+	}
+}
+
+// ClientAfter functions executed on the metadata received
+func ClientAfter(after ...ClientResponseFunc) ClientOption {
+	return func(c *Client) {
+		c.after = append(c.after, after...)
+	}
+}
+
+// ClientCallOptions call options used on every call, e.g. grpc.WaitForReady(true)
+func ClientCallOptions(opts ...grpc.CallOption) ClientOption {
+	return func(c *Client) {
+		c.opts = append(c.opts, opts...)
+	}
+}
+
+// Endpoint returns an endpoint that calls the remote method. Status errors are returned as
+// responses with the equivalent HTTP status and {"message": ...}, like the HTTP client does
+// with error statuses, so the same middlewares (CircuitBreaker...) apply.
+func (c *Client) Endpoint() endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+		if ctx == nil {
+			ctx = context.Background()
+		}
+
+		req, err := c.enc(ctx, request)
+		if err != nil {
+			return nil, err
+		}
+
+		md := metadata.MD{}
+		if outgoing, ok := metadata.FromOutgoingContext(ctx); ok {
+			md = outgoing.Copy()
+		}
+
+		for _, f := range c.before {
+			ctx = f(ctx, &md)
+		}
+		ctx = metadata.NewOutgoingContext(ctx, md)
+
+		var header, trailer metadata.MD
+		opts := append([]grpc.CallOption{grpc.Header(&header), grpc.Trailer(&trailer)}, c.opts...)
+
+		reply := reflect.New(c.reply).Interface()
+		err = c.conn.Invoke(ctx, c.method, req, reply, opts...)
+
+		for _, f := range c.after {
+			ctx = f(ctx, header, trailer)
+		}
+
+		if err != nil {
+			st, ok := status.FromError(err)
+			if !ok {
+				return nil, err
+			}
+			return endpoint.Response(StatusFromCode(st.Code()), map[string]string{"message": st.Message()}), nil
+		}
+
+		return c.dec(ctx, reply)
+	}
+}
+
+// PropagateAuthToken sends the token of the context (see auth.TokenFromContext) as authorization metadata
+func PropagateAuthToken(ctx context.Context, md *metadata.MD) context.Context {
+	if token := auth.TokenFromContext(ctx); token != "" {
+		md.Set("authorization", token)
+	}
+	return ctx
+}
+
+// EncodeMessage uses the endpoint request as the request message
+func EncodeMessage(ctx context.Context, request interface{}) (interface{}, error) {
+	return request, nil
+}
+
+// DecodeReply uses the reply message as EndpointResponse.Data
+func DecodeReply(ctx context.Context, reply interface{}) (endpoint.EndpointResponse, error) {
+	return endpoint.Response(200, reply), nil
+}
@@ -0,0 +1,77 @@
+package grpctransport
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/helderfarias/go-api-kit/endpoint"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// UnaryServerInterceptor applies the middlewares (the first is the outermost) to every unary
+// method of the server, so JWT, Authorize, RateLimit... work on services not built with Server.
+// The before functions run first on the incoming metadata, e.g. PopulateAuthToken.
+func UnaryServerInterceptor(before []ServerRequestFunc, middlewares ...endpoint.Middleware) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		md, ok := metadata.FromIncomingContext(ctx)
+		if !ok {
+			md = metadata.MD{}
+		}
+
+		for _, f := range before {
+			ctx = f(ctx, md)
+		}
+
+		e := chain(func(ctx context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+			reply, err := handler(ctx, request)
+			if err != nil {
+				return nil, err
+			}
+			return endpoint.Response(http.StatusOK, reply), nil
+		}, middlewares)
+
+		resp, err := e(ctx, req)
+		if err != nil {
+			return nil, StatusError(err)
+		}
+
+		if err := responseError(resp); err != nil {
+			return nil, err
+		}
+
+		if resp == nil {
+			return nil, nil
+		}
+
+		return resp.Data(), nil
+	}
+}
+
+// UnaryClientInterceptor applies the middlewares (the first is the outermost) to every unary call
+// of the connection, so CircuitBreaker, Bulkhead... protect clients not built with Client.
+// Responses with error codes returned by the middlewares become status errors.
+func UnaryClientInterceptor(middlewares ...endpoint.Middleware) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		e := chain(func(ctx context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+			if err := invoker(ctx, method, request, reply, cc, opts...); err != nil {
+				return nil, err
+			}
+			return endpoint.Response(http.StatusOK, reply), nil
+		}, middlewares)
+
+		resp, err := e(ctx, req)
+		if err != nil {
+			return StatusError(err)
+		}
+
+		return responseError(resp)
+	}
+}
+
+func chain(e endpoint.Endpoint, middlewares []endpoint.Middleware) endpoint.Endpoint {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		e = middlewares[i](e)
+	}
+	return e
+}
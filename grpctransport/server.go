@@ -0,0 +1,156 @@
+// Package grpctransport gRPC bindings for endpoint.Endpoint
+package grpctransport
+
+import (
+	"context"
+	"strings"
+
+	"github.com/helderfarias/go-api-kit/auth"
+	"github.com/helderfarias/go-api-kit/endpoint"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// DecodeRequestFunc converts the gRPC request message into the endpoint request
+type DecodeRequestFunc func(ctx context.Context, grpcReq interface{}) (interface{}, error)
+
+// EncodeResponseFunc converts the endpoint response into the gRPC reply message
+type EncodeResponseFunc func(ctx context.Context, resp endpoint.EndpointResponse) (interface{}, error)
+
+// ServerRequestFunc runs before decoding, may enrich the context from the incoming metadata
+type ServerRequestFunc func(ctx context.Context, md metadata.MD) context.Context
+
+// ServerResponseFunc runs after the endpoint, may add header and trailer metadata
+type ServerResponseFunc func(ctx context.Context, header *metadata.MD, trailer *metadata.MD) context.Context
+
+// Handler the unary handler called by the generated service implementation
+type Handler interface {
+	ServeGRPC(ctx context.Context, request interface{}) (context.Context, interface{}, error)
+}
+
+// Server wraps an endpoint as a gRPC unary handler
+type Server struct {
+	e      endpoint.Endpoint
+	dec    DecodeRequestFunc
+	enc    EncodeResponseFunc
+	before []ServerRequestFunc
+	after  []ServerResponseFunc
+}
+
+// ServerOption sets an optional parameter for servers
+type ServerOption func(s *Server)
+
+// NewServer constructs a new server, the service implementation delegates its methods to it:
+//
+//	func (s *addressService) Find(ctx context.Context, req *pb.FindRequest) (*pb.Address, error) {
+//		_, reply, err := s.find.ServeGRPC(ctx, req)
+//		if err != nil {
+//			return nil, err
+//		}
+//		return reply.(*pb.Address), nil
+//	}
+func NewServer(e endpoint.Endpoint, dec DecodeRequestFunc, enc EncodeResponseFunc, options ...ServerOption) *Server {
+	s := &Server{
+		e:   e,
+		dec: dec,
+		enc: enc,
+	}
+
+	for _, o := range options {
+		o(s)
+	}
+
+	return s
+}
+
+// ServerBefore functions executed on the incoming metadata before the request is decoded
+func ServerBefore(before ...ServerRequestFunc) ServerOption {
+	return func(s *Server) {
+		s.before = append(s.before, before...)
+	}
+}
+
+// ServerAfter functions executed after the endpoint is invoked
+func ServerAfter(after ...ServerResponseFunc) ServerOption {
+	return func(s *Server) {
+		s.after = append(s.after, after...)
+	}
+}
+
+// ServeGRPC implements Handler. The incoming metadata stays in the context (metadata.FromIncomingContext),
+// the response headers are sent as header metadata and responses with error codes or errors are
+// returned as status errors.
+func (s *Server) ServeGRPC(ctx context.Context, request interface{}) (context.Context, interface{}, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		md = metadata.MD{}
+	}
+
+	for _, f := range s.before {
+		ctx = f(ctx, md)
+	}
+
+	req, err := s.dec(ctx, request)
+	if err != nil {
+		logrus.WithField("grpctransport.decode", request).Debug(err)
+		return ctx, nil, StatusError(err)
+	}
+
+	resp, err := s.e(ctx, req)
+	if err != nil {
+		logrus.WithField("grpctransport.endpoint", req).Debug(err)
+		return ctx, nil, StatusError(err)
+	}
+
+	header, trailer := metadata.MD{}, metadata.MD{}
+	for key, values := range endpoint.HeadersOf(resp) {
+		header.Append(strings.ToLower(key), values...)
+	}
+
+	for _, f := range s.after {
+		ctx = f(ctx, &header, &trailer)
+	}
+
+	if len(header) > 0 {
+		grpc.SetHeader(ctx, header)
+	}
+	if len(trailer) > 0 {
+		grpc.SetTrailer(ctx, trailer)
+	}
+
+	if err := responseError(resp); err != nil {
+		return ctx, nil, err
+	}
+
+	reply, err := s.enc(ctx, resp)
+	if err != nil {
+		logrus.WithField("grpctransport.encode", req).Debug(err)
+		return ctx, nil, StatusError(err)
+	}
+
+	return ctx, reply, nil
+}
+
+// PopulateAuthToken stores the authorization metadata token for the JWT middleware
+func PopulateAuthToken(ctx context.Context, md metadata.MD) context.Context {
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return ctx
+	}
+
+	return auth.ContextWithToken(ctx, values[0])
+}
+
+// EncodeData uses EndpointResponse.Data as reply, for endpoints returning the proto messages
+func EncodeData(ctx context.Context, resp endpoint.EndpointResponse) (interface{}, error) {
+	if resp == nil {
+		return nil, nil
+	}
+	return resp.Data(), nil
+}
+
+// DecodeMessage uses the request message as the endpoint request
+func DecodeMessage(ctx context.Context, grpcReq interface{}) (interface{}, error) {
+	return grpcReq, nil
+}
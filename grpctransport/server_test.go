@@ -0,0 +1,179 @@
+package grpctransport
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/helderfarias/go-api-kit/apierror"
+	"github.com/helderfarias/go-api-kit/auth"
+	"github.com/helderfarias/go-api-kit/endpoint"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type addressService struct {
+	find Handler
+}
+
+// addressServiceDesc hand written equivalent of the generated code of
+// service Addresses { rpc Find(google.protobuf.StringValue) returns (google.protobuf.StringValue); }
+var addressServiceDesc = grpc.ServiceDesc{
+	ServiceName: "test.Addresses",
+	HandlerType: (*interface{})(nil),
+	Methods: []grpc.MethodDesc{{
+		MethodName: "Find",
+		Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+			in := &wrapperspb.StringValue{}
+			if err := dec(in); err != nil {
+				return nil, err
+			}
+
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				_, reply, err := srv.(*addressService).find.ServeGRPC(ctx, req)
+				return reply, err
+			}
+
+			if interceptor == nil {
+				return handler(ctx, in)
+			}
+			return interceptor(ctx, in, &grpc.UnaryServerInfo{Server: srv, FullMethod: "/test.Addresses/Find"}, handler)
+		},
+	}},
+}
+
+func findAddress(ctx context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+	street := request.(*wrapperspb.StringValue).Value
+
+	switch street {
+	case "missing":
+		return endpoint.Response(404, map[string]string{"message": "address not found"}), nil
+	case "invalid":
+		return nil, apierror.BadRequest("invalid street")
+	}
+
+	return endpoint.WithHeader(endpoint.Response(200, wrapperspb.String(street+" by "+auth.TokenFromContext(ctx))), "X-Source", "db"), nil
+}
+
+func dial(t *testing.T, find Handler, opts ...grpc.ServerOption) (*grpc.ClientConn, func()) {
+	listener := bufconn.Listen(1024 * 1024)
+
+	server := grpc.NewServer(opts...)
+	server.RegisterService(&addressServiceDesc, &addressService{find: find})
+	go server.Serve(listener)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithInsecure(),
+		grpc.WithContextDialer(func(ctx context.Context, s string) (net.Conn, error) { return listener.Dial() }),
+	)
+	assert.Nil(t, err)
+
+	return conn, func() {
+		conn.Close()
+		server.Stop()
+	}
+}
+
+func TestServerAndClientEndpoint(t *testing.T) {
+	conn, stop := dial(t, NewServer(findAddress, DecodeMessage, EncodeData, ServerBefore(PopulateAuthToken)))
+	defer stop()
+
+	var header metadata.MD
+	client := NewClient(conn, "test.Addresses", "Find", EncodeMessage, DecodeReply, wrapperspb.StringValue{},
+		ClientBefore(PropagateAuthToken),
+		ClientAfter(func(ctx context.Context, h metadata.MD, t metadata.MD) context.Context {
+			header = h
+			return ctx
+		}),
+	)
+
+	resp, err := client.Endpoint()(auth.ContextWithToken(context.Background(), "token"), wrapperspb.String("Main"))
+
+	assert.Nil(t, err)
+	assert.Equal(t, 200, resp.Code())
+	assert.Equal(t, "Main by token", resp.Data().(*wrapperspb.StringValue).Value)
+	assert.Equal(t, []string{"db"}, header.Get("x-source"))
+
+	resp, err = client.Endpoint()(nil, wrapperspb.String("missing"))
+
+	assert.Nil(t, err)
+	assert.Equal(t, 404, resp.Code())
+	assert.Equal(t, map[string]string{"message": "address not found"}, resp.Data())
+}
+
+func TestServerMapsErrorsToStatus(t *testing.T) {
+	_, _, err := NewServer(findAddress, DecodeMessage, EncodeData).ServeGRPC(context.Background(), wrapperspb.String("invalid"))
+
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Equal(t, "invalid street", status.Convert(err).Message())
+	assert.Equal(t, codes.Unavailable, CodeFromStatus(503))
+	assert.Equal(t, 401, StatusFromCode(codes.Unauthenticated))
+}
+
+func TestStatusErrorHidesInternalErrors(t *testing.T) {
+	internal := StatusError(errors.New("pq: connection refused"))
+	notFound := StatusError(apierror.New(404, "address_not_found", "address not found"))
+
+	assert.Equal(t, codes.Internal, status.Code(internal))
+	assert.Equal(t, "Internal Server Error", status.Convert(internal).Message())
+	assert.Equal(t, codes.NotFound, status.Code(notFound))
+	assert.Equal(t, "address not found", status.Convert(notFound).Message())
+}
+
+func TestResponseErrorHidesServerErrorData(t *testing.T) {
+	unavailable := responseError(endpoint.Response(503, map[string]string{"message": "redis: dial tcp 10.0.0.5:6379"}))
+	notFound := responseError(endpoint.Response(404, map[string]string{"message": "address not found"}))
+
+	assert.Equal(t, codes.Unavailable, status.Code(unavailable))
+	assert.Equal(t, "Service Unavailable", status.Convert(unavailable).Message())
+	assert.Equal(t, "address not found", status.Convert(notFound).Message())
+}
+
+func TestInterceptorsApplyMiddlewares(t *testing.T) {
+	var calls []string
+	trace := func(name string) endpoint.Middleware {
+		return func(next endpoint.Endpoint) endpoint.Endpoint {
+			return func(ctx context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+				calls = append(calls, name)
+				if auth.TokenFromContext(ctx) == "" {
+					return endpoint.Response(401, map[string]string{"message": "missing token"}), nil
+				}
+				return next(ctx, request)
+			}
+		}
+	}
+
+	conn, stop := dial(t,
+		NewServer(findAddress, DecodeMessage, EncodeData),
+		grpc.UnaryInterceptor(UnaryServerInterceptor([]ServerRequestFunc{PopulateAuthToken}, trace("server"))),
+	)
+	defer stop()
+
+	reply := &wrapperspb.StringValue{}
+	err := conn.Invoke(context.Background(), "/test.Addresses/Find", wrapperspb.String("Main"), reply)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "token")
+	interceptor := UnaryClientInterceptor(func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+			calls = append(calls, "client")
+			return next(ctx, request)
+		}
+	})
+
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		return cc.Invoke(ctx, method, req, reply, opts...)
+	}
+
+	err = interceptor(ctx, "/test.Addresses/Find", wrapperspb.String("Main"), reply, conn, invoker)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "Main by token", reply.Value)
+	assert.Equal(t, []string{"server", "client", "server"}, calls)
+}
@@ -0,0 +1,120 @@
+package grpctransport
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/helderfarias/go-api-kit/apierror"
+	"github.com/helderfarias/go-api-kit/endpoint"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// httpCodes gRPC codes of the HTTP statuses
+var httpCodes = map[int]codes.Code{
+	http.StatusOK:                  codes.OK,
+	http.StatusBadRequest:          codes.InvalidArgument,
+	http.StatusUnauthorized:        codes.Unauthenticated,
+	http.StatusForbidden:           codes.PermissionDenied,
+	http.StatusNotFound:            codes.NotFound,
+	http.StatusConflict:            codes.AlreadyExists,
+	http.StatusPreconditionFailed:  codes.FailedPrecondition,
+	http.StatusUnprocessableEntity: codes.InvalidArgument,
+	http.StatusTooManyRequests:     codes.ResourceExhausted,
+	499:                            codes.Canceled,
+	http.StatusNotImplemented:      codes.Unimplemented,
+	http.StatusServiceUnavailable:  codes.Unavailable,
+	http.StatusGatewayTimeout:      codes.DeadlineExceeded,
+}
+
+// grpcStatuses HTTP statuses of the gRPC codes
+var grpcStatuses = map[codes.Code]int{
+	codes.OK:                 http.StatusOK,
+	codes.Canceled:           499,
+	codes.Unknown:            http.StatusInternalServerError,
+	codes.InvalidArgument:    http.StatusBadRequest,
+	codes.DeadlineExceeded:   http.StatusGatewayTimeout,
+	codes.NotFound:           http.StatusNotFound,
+	codes.AlreadyExists:      http.StatusConflict,
+	codes.PermissionDenied:   http.StatusForbidden,
+	codes.ResourceExhausted:  http.StatusTooManyRequests,
+	codes.FailedPrecondition: http.StatusPreconditionFailed,
+	codes.Aborted:            http.StatusConflict,
+	codes.OutOfRange:         http.StatusBadRequest,
+	codes.Unimplemented:      http.StatusNotImplemented,
+	codes.Internal:           http.StatusInternalServerError,
+	codes.Unavailable:        http.StatusServiceUnavailable,
+	codes.DataLoss:           http.StatusInternalServerError,
+	codes.Unauthenticated:    http.StatusUnauthorized,
+}
+
+// CodeFromStatus gRPC code of the HTTP status, other 4xx are FailedPrecondition and 5xx Internal
+func CodeFromStatus(httpStatus int) codes.Code {
+	if code, ok := httpCodes[httpStatus]; ok {
+		return code
+	}
+	if httpStatus >= 400 && httpStatus < 500 {
+		return codes.FailedPrecondition
+	}
+	if httpStatus >= 500 {
+		return codes.Internal
+	}
+	return codes.OK
+}
+
+// StatusFromCode HTTP status of the gRPC code
+func StatusFromCode(code codes.Code) int {
+	if s, ok := grpcStatuses[code]; ok {
+		return s
+	}
+	return http.StatusInternalServerError
+}
+
+// StatusError converts errors into gRPC status errors, status errors are kept and other
+// errors are mapped by the status and message of apierror.Public
+func StatusError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	e := apierror.Public(err)
+	return status.Error(CodeFromStatus(e.StatusCode()), e.Message)
+}
+
+// responseError status error of responses with error codes (>= 400), the message of 4xx is
+// taken from the data ({"message": ...}, apierror.Problem, error), 5xx get the status text
+func responseError(resp endpoint.EndpointResponse) error {
+	if resp == nil || resp.Code() < http.StatusBadRequest {
+		return nil
+	}
+
+	message := http.StatusText(resp.Code())
+	if resp.Code() >= http.StatusInternalServerError {
+		logrus.WithField("grpctransport.response", resp.Code()).Error(resp.Data())
+		return status.Error(CodeFromStatus(resp.Code()), message)
+	}
+
+	switch data := resp.Data().(type) {
+	case map[string]string:
+		if m, ok := data["message"]; ok {
+			message = m
+		}
+	case map[string]interface{}:
+		if m, ok := data["message"]; ok {
+			message = fmt.Sprint(m)
+		}
+	case apierror.Problem:
+		if data.Detail != "" {
+			message = data.Detail
+		}
+	case error:
+		message = data.Error()
+	}
+
+	return status.Error(CodeFromStatus(resp.Code()), message)
+}
@@ -0,0 +1,100 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/helderfarias/go-api-kit/cache"
+	"github.com/helderfarias/go-api-kit/cron"
+	"github.com/helderfarias/go-api-kit/db"
+)
+
+// Connection a message broker connection, implemented by *nats.Conn and the mb nats server
+type Connection interface {
+	IsConnected() bool
+}
+
+// DB pings the database of the factory
+func DB(factory db.ConnectionFactory) Checker {
+	return CheckerFunc(func(ctx context.Context) (Details, error) {
+		if factory == nil {
+			return nil, errors.New("database is not configured")
+		}
+
+		delegate := factory.Delegate()
+
+		details := Details{}
+		if d, ok := delegate.(interface{ DriverName() string }); ok {
+			details["database"] = d.DriverName()
+		}
+		if s, ok := delegate.(interface{ Stats() sql.DBStats }); ok {
+			stats := s.Stats()
+			details["openConnections"] = stats.OpenConnections
+			details["inUse"] = stats.InUse
+		}
+
+		pinger, ok := delegate.(interface{ PingContext(context.Context) error })
+		if !ok {
+			return details, errors.New("database does not support ping")
+		}
+
+		return details, pinger.PingContext(ctx)
+	})
+}
+
+// Cache pings Redis, the memory cache is always UP
+func Cache(server cache.CacheServer) Checker {
+	return CheckerFunc(func(ctx context.Context) (Details, error) {
+		if server == nil {
+			return nil, errors.New("cache is not configured")
+		}
+
+		pinger, ok := server.(cache.Pinger)
+		if !ok {
+			return Details{"type": "memory"}, nil
+		}
+
+		return Details{"type": "redis"}, pinger.Ping()
+	})
+}
+
+// NATS reports the connection status
+func NATS(conn Connection) Checker {
+	return CheckerFunc(func(ctx context.Context) (Details, error) {
+		if conn == nil || !conn.IsConnected() {
+			return nil, errors.New("nats is not connected")
+		}
+
+		return nil, nil
+	})
+}
+
+// Cron reports the scheduler DOWN once stopped or when the next run is late by more than grace,
+// register it with CheckOptions.Liveness as a stuck scheduler needs a restart
+func Cron(task *cron.FutureTask, grace time.Duration) Checker {
+	return CheckerFunc(func(ctx context.Context) (Details, error) {
+		if task == nil || !task.Running() {
+			return nil, errors.New("scheduler is stopped")
+		}
+
+		details := Details{}
+
+		if last := task.LastRun(); !last.IsZero() {
+			details["lastRun"] = last.Format(time.RFC3339)
+		}
+
+		next := task.Next()
+		if next.IsZero() {
+			return details, errors.New("scheduler has no scheduled run")
+		}
+		details["nextRun"] = next.Format(time.RFC3339)
+
+		if time.Since(next) > grace {
+			return details, errors.New("scheduler is late")
+		}
+
+		return details, nil
+	})
+}
@@ -0,0 +1,53 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/helderfarias/go-api-kit/router"
+)
+
+// LiveHandler serves the liveness probe (/health/live), 503 unless UP. The component details
+// are only served with SetShowDetails.
+func (h *Health) LiveHandler() http.Handler {
+	return h.handler(h.Live)
+}
+
+// ReadyHandler serves the readiness probe (/health/ready), 503 unless UP
+func (h *Health) ReadyHandler() http.Handler {
+	return h.handler(h.Ready)
+}
+
+// Mount registers GET prefix, prefix/live and prefix/ready, e.g. Mount(r, "/health")
+func (h *Health) Mount(r *router.Router, prefix string) {
+	r.Handle(http.MethodGet, prefix, h.ReadyHandler())
+	r.Handle(http.MethodGet, prefix+"/live", h.LiveHandler())
+	r.Handle(http.MethodGet, prefix+"/ready", h.ReadyHandler())
+}
+
+func (h *Health) handler(probe func(ctx context.Context) Report) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := probe(r.Context())
+
+		h.mu.RLock()
+		showDetails := h.showDetails
+		h.mu.RUnlock()
+
+		if !showDetails {
+			for name, component := range report.Components {
+				report.Components[name] = Component{Status: component.Status}
+			}
+		}
+
+		status := http.StatusOK
+		if report.Status != StatusUp {
+			status = http.StatusServiceUnavailable
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(report)
+	})
+}
@@ -0,0 +1,261 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Status health of a component, same values as Spring Boot Actuator
+type Status string
+
+const (
+	StatusUp           Status = "UP"
+	StatusDown         Status = "DOWN"
+	StatusOutOfService Status = "OUT_OF_SERVICE"
+	StatusUnknown      Status = "UNKNOWN"
+)
+
+// Details extra information reported with the component status
+type Details map[string]interface{}
+
+// Checker checks a dependency, a non nil error reports the component DOWN
+type Checker interface {
+	Check(ctx context.Context) (Details, error)
+}
+
+// CheckerFunc adapts a function to Checker
+type CheckerFunc func(ctx context.Context) (Details, error)
+
+// CheckOptions how a check is run
+type CheckOptions struct {
+	// Timeout of a single check, defaults to 3s
+	Timeout time.Duration
+	// CacheTTL reuses the last result for the period, defaults to 1s, negative disables
+	CacheTTL time.Duration
+	// Liveness the check also takes part of the liveness probe (e.g. the cron scheduler),
+	// checks of external dependencies should only affect readiness
+	Liveness bool
+}
+
+// Component result of a check
+type Component struct {
+	Status  Status  `json:"status"`
+	Details Details `json:"details,omitempty"`
+}
+
+// Report aggregated result in the Actuator format, e.g.
+//
+//	{"status":"DOWN","components":{"db":{"status":"DOWN","details":{"error":"timeout"}}}}
+type Report struct {
+	Status     Status               `json:"status"`
+	Components map[string]Component `json:"components,omitempty"`
+}
+
+// Health registry of checks
+type Health struct {
+	mu           sync.RWMutex
+	checks       map[string]*check
+	outOfService bool
+	showDetails  bool
+}
+
+type check struct {
+	name    string
+	checker Checker
+	options CheckOptions
+
+	mu        sync.Mutex
+	result    Component
+	checkedAt time.Time
+}
+
+// New constructs an empty registry
+func New() *Health {
+	return &Health{checks: map[string]*check{}}
+}
+
+func (f CheckerFunc) Check(ctx context.Context) (Details, error) {
+	return f(ctx)
+}
+
+// Register adds or replaces the check named name
+func (h *Health) Register(name string, checker Checker, options ...CheckOptions) {
+	option := CheckOptions{}
+	for _, o := range options {
+		option = o
+	}
+
+	if option.Timeout == 0 {
+		option.Timeout = 3 * time.Second
+	}
+
+	if option.CacheTTL == 0 {
+		option.CacheTTL = time.Second
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.checks[name] = &check{name: name, checker: checker, options: option}
+}
+
+// SetOutOfService reports readiness OUT_OF_SERVICE, used while shutting down so load
+// balancers stop routing before the server closes
+func (h *Health) SetOutOfService(outOfService bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.outOfService = outOfService
+}
+
+// SetShowDetails serves the details of the components (e.g. the error of a DOWN database) from
+// the handlers, off by default like Actuator because they may reveal hosts and users
+func (h *Health) SetShowDetails(show bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.showDetails = show
+}
+
+// Live runs the liveness checks, UP when there are none
+func (h *Health) Live(ctx context.Context) Report {
+	return h.run(ctx, true)
+}
+
+// Ready runs all the checks
+func (h *Health) Ready(ctx context.Context) Report {
+	report := h.run(ctx, false)
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if h.outOfService {
+		report.Status = StatusOutOfService
+	}
+
+	return report
+}
+
+func (h *Health) run(ctx context.Context, liveness bool) Report {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	h.mu.RLock()
+	checks := []*check{}
+	for _, c := range h.checks {
+		if !liveness || c.options.Liveness {
+			checks = append(checks, c)
+		}
+	}
+	h.mu.RUnlock()
+
+	sort.Slice(checks, func(i, j int) bool { return checks[i].name < checks[j].name })
+
+	results := make([]Component, len(checks))
+
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func(i int, c *check) {
+			defer wg.Done()
+			results[i] = c.run(ctx)
+		}(i, c)
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusUp}
+	if len(checks) > 0 {
+		report.Components = map[string]Component{}
+	}
+
+	for i, c := range checks {
+		report.Components[c.name] = results[i]
+		report.Status = aggregate(report.Status, results[i].Status)
+	}
+
+	return report
+}
+
+func (c *check) run(ctx context.Context) Component {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.options.CacheTTL > 0 && !c.checkedAt.IsZero() && time.Since(c.checkedAt) < c.options.CacheTTL {
+		return c.result
+	}
+
+	result := c.execute(ctx)
+	if ctx.Err() != nil {
+		// the caller is gone, e.g. the client disconnected, the result says nothing of the component
+		return result
+	}
+
+	c.result = result
+	c.checkedAt = time.Now()
+
+	return c.result
+}
+
+func (c *check) execute(parent context.Context) Component {
+	ctx, cancel := context.WithTimeout(parent, c.options.Timeout)
+	defer cancel()
+
+	type outcome struct {
+		details Details
+		err     error
+	}
+
+	done := make(chan outcome, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- outcome{err: fmt.Errorf("%v", r)}
+			}
+		}()
+
+		details, err := c.checker.Check(ctx)
+		done <- outcome{details: details, err: err}
+	}()
+
+	select {
+	case o := <-done:
+		if o.err != nil {
+			return down(o.details, o.err)
+		}
+		return Component{Status: StatusUp, Details: o.details}
+	case <-ctx.Done():
+		if parent.Err() != nil {
+			return down(nil, fmt.Errorf("check canceled: %v", parent.Err()))
+		}
+		return down(nil, fmt.Errorf("check timed out after %v", c.options.Timeout))
+	}
+}
+
+func down(details Details, err error) Component {
+	if details == nil {
+		details = Details{}
+	}
+	details["error"] = err.Error()
+
+	return Component{Status: StatusDown, Details: details}
+}
+
+// aggregate keeps the most severe status: DOWN, OUT_OF_SERVICE, UP, UNKNOWN
+func aggregate(current, next Status) Status {
+	if severity(next) > severity(current) {
+		return next
+	}
+	return current
+}
+
+func severity(s Status) int {
+	switch s {
+	case StatusDown:
+		return 3
+	case StatusOutOfService:
+		return 2
+	case StatusUp:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,199 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/helderfarias/go-api-kit/cache"
+	"github.com/helderfarias/go-api-kit/cron"
+	"github.com/helderfarias/go-api-kit/router"
+	wrapper "github.com/helderfarias/sqlx-wrapper/db"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeDB struct {
+	err error
+}
+
+type fakeFactory struct {
+	db *fakeDB
+}
+
+type fakeConn bool
+
+func (f *fakeDB) PingContext(ctx context.Context) error {
+	return f.err
+}
+
+func (f *fakeDB) DriverName() string {
+	return "postgres"
+}
+
+func (f *fakeFactory) NewConnection() wrapper.UnitOfWork {
+	return nil
+}
+
+func (f *fakeFactory) NewConnectionWithTransaction() (wrapper.UnitOfWork, error) {
+	return nil, nil
+}
+
+func (f *fakeFactory) Delegate() interface{} {
+	return f.db
+}
+
+func (f *fakeFactory) Close() error {
+	return nil
+}
+
+func (c fakeConn) IsConnected() bool {
+	return bool(c)
+}
+
+func up(ctx context.Context) (Details, error) {
+	return nil, nil
+}
+
+func TestReadyAggregatesComponents(t *testing.T) {
+	h := New()
+	h.Register("db", DB(&fakeFactory{db: &fakeDB{}}))
+	h.Register("nats", NATS(fakeConn(false)))
+	h.Register("cache", Cache(cache.NewCacheServer()))
+
+	report := h.Ready(context.Background())
+
+	assert.Equal(t, StatusDown, report.Status)
+	assert.Equal(t, StatusUp, report.Components["db"].Status)
+	assert.Equal(t, "postgres", report.Components["db"].Details["database"])
+	assert.Equal(t, StatusUp, report.Components["cache"].Status)
+	assert.Equal(t, StatusDown, report.Components["nats"].Status)
+	assert.Equal(t, "nats is not connected", report.Components["nats"].Details["error"])
+}
+
+func TestLiveOnlyRunsLivenessChecks(t *testing.T) {
+	h := New()
+	h.Register("db", DB(&fakeFactory{db: &fakeDB{err: errors.New("refused")}}))
+
+	assert.Equal(t, Report{Status: StatusUp}, h.Live(context.Background()))
+
+	h.Register("ping", CheckerFunc(up), CheckOptions{Liveness: true})
+
+	report := h.Live(context.Background())
+	assert.Equal(t, StatusUp, report.Status)
+	assert.Len(t, report.Components, 1)
+}
+
+func TestCheckTimeoutAndPanic(t *testing.T) {
+	h := New()
+	h.Register("slow", CheckerFunc(func(ctx context.Context) (Details, error) {
+		time.Sleep(time.Second)
+		return nil, nil
+	}), CheckOptions{Timeout: 10 * time.Millisecond})
+	h.Register("panic", CheckerFunc(func(ctx context.Context) (Details, error) {
+		panic("boom")
+	}))
+
+	report := h.Ready(context.Background())
+
+	assert.Equal(t, StatusDown, report.Components["slow"].Status)
+	assert.Contains(t, report.Components["slow"].Details["error"], "timed out")
+	assert.Equal(t, "boom", report.Components["panic"].Details["error"])
+}
+
+func TestCheckCachesResult(t *testing.T) {
+	calls := int32(0)
+	counter := CheckerFunc(func(ctx context.Context) (Details, error) {
+		atomic.AddInt32(&calls, 1)
+		return nil, nil
+	})
+
+	h := New()
+	h.Register("cached", counter, CheckOptions{CacheTTL: time.Minute})
+	h.Ready(context.Background())
+	h.Ready(context.Background())
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	h.Register("cached", counter, CheckOptions{CacheTTL: -1})
+	h.Ready(context.Background())
+	h.Ready(context.Background())
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestCronCheck(t *testing.T) {
+	task := cron.NewSchedule(cron.Every("1h")).Run(func() {})
+
+	component := New()
+	component.Register("cron", Cron(task, time.Minute), CheckOptions{Liveness: true})
+	assert.Equal(t, StatusUp, component.Live(context.Background()).Status)
+
+	task.Stop()
+
+	stopped := New()
+	stopped.Register("cron", Cron(task, time.Minute), CheckOptions{Liveness: true})
+	report := stopped.Live(context.Background())
+	assert.Equal(t, StatusDown, report.Status)
+	assert.Equal(t, "scheduler is stopped", report.Components["cron"].Details["error"])
+}
+
+func TestHandlers(t *testing.T) {
+	h := New()
+	h.Register("nats", NATS(fakeConn(true)))
+
+	r := router.NewRouter()
+	h.Mount(r, "/health")
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/health/ready", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"status":"UP","components":{"nats":{"status":"UP"}}}`, rec.Body.String())
+
+	h.SetOutOfService(true)
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/health/ready", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	report := Report{}
+	json.Unmarshal(rec.Body.Bytes(), &report)
+	assert.Equal(t, StatusOutOfService, report.Status)
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/health/live", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"status":"UP"}`, rec.Body.String())
+}
+
+func TestHandlersHideDetailsByDefault(t *testing.T) {
+	h := New()
+	h.Register("db", CheckerFunc(func(ctx context.Context) (Details, error) {
+		return Details{"database": "postgres"}, errors.New("dial tcp 10.0.0.5:5432: connection refused")
+	}))
+
+	rec := httptest.NewRecorder()
+	h.ReadyHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/health", nil))
+	assert.JSONEq(t, `{"status":"DOWN","components":{"db":{"status":"DOWN"}}}`, rec.Body.String())
+
+	h.SetShowDetails(true)
+
+	rec = httptest.NewRecorder()
+	h.ReadyHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/health", nil))
+	assert.Contains(t, rec.Body.String(), "connection refused")
+}
+
+func TestCanceledCheckIsNotCached(t *testing.T) {
+	h := New()
+	h.Register("db", CheckerFunc(func(ctx context.Context) (Details, error) {
+		return nil, ctx.Err()
+	}), CheckOptions{CacheTTL: time.Minute})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	assert.Equal(t, StatusDown, h.Ready(ctx).Status)
+	assert.Equal(t, StatusUp, h.Ready(context.Background()).Status)
+}
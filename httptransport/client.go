@@ -0,0 +1,189 @@
+package httptransport
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/helderfarias/go-api-kit/endpoint"
+	"github.com/helderfarias/go-api-kit/uri"
+	"gopkg.in/resty.v1"
+)
+
+// EncodeRequestFunc fills the outgoing request (path params, query, headers, body)
+type EncodeRequestFunc func(ctx context.Context, r *resty.Request, request interface{}) error
+
+// DecodeResponseFunc converts the remote response into an endpoint response
+type DecodeResponseFunc func(ctx context.Context, resp *resty.Response) (endpoint.EndpointResponse, error)
+
+// ClientRequestFunc runs before the request is sent, e.g. to propagate headers
+type ClientRequestFunc func(ctx context.Context, r *resty.Request) context.Context
+
+// ClientResponseFunc runs after the response is received and before decoding
+type ClientResponseFunc func(ctx context.Context, resp *resty.Response) context.Context
+
+// Client wraps a remote route as an endpoint
+type Client struct {
+	client *resty.Client
+	method string
+	target string
+	enc    EncodeRequestFunc
+	dec    DecodeResponseFunc
+	before []ClientRequestFunc
+	after  []ClientResponseFunc
+}
+
+// ClientOption sets an optional parameter for clients
+type ClientOption func(c *Client)
+
+// NewClient constructs a client for the route, the target may contain path params
+// like /users/{id} which are filled by the encoder with r.SetPathParams
+func NewClient(method string, target *uri.URI, enc EncodeRequestFunc, dec DecodeResponseFunc, options ...ClientOption) *Client {
+	c := &Client{
+		client: resty.New().SetDisableWarn(true),
+		method: method,
+		target: target.Template(),
+		enc:    enc,
+		dec:    dec,
+	}
+
+	for _, o := range options {
+		o(c)
+	}
+
+	return c
+}
+
+// SetClient sets the resty client used (timeouts, retries, TLS...)
+func SetClient(client *resty.Client) ClientOption {
+	return func(c *Client) {
+		c.client = client
+	}
+}
+
+// ClientBefore functions executed on the outgoing request
+func ClientBefore(before ...ClientRequestFunc) ClientOption {
+	return func(c *Client) {
+		c.before = append(c.before, before...)
+	}
+}
+
+// ClientAfter functions executed on the incoming response
+func ClientAfter(after ...ClientResponseFunc) ClientOption {
+	return func(c *Client) {
+		c.after = append(c.after, after...)
+	}
+}
+
+// Endpoint returns an endpoint that invokes the remote route, so the
+// same middlewares of the server side (Cacheable, CircuitBreaker...) apply
+func (c *Client) Endpoint() endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+		if ctx == nil {
+			ctx = context.Background()
+		}
+
+		req := c.client.R().SetContext(ctx)
+
+		if err := c.enc(ctx, req, request); err != nil {
+			return nil, err
+		}
+
+		for _, f := range c.before {
+			ctx = f(ctx, req)
+		}
+
+		resp, err := req.Execute(c.method, c.target)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, f := range c.after {
+			ctx = f(ctx, resp)
+		}
+
+		return c.dec(ctx, resp)
+	}
+}
+
+// NopRequestEncoder sends the request without body
+func NopRequestEncoder(ctx context.Context, r *resty.Request, request interface{}) error {
+	return nil
+}
+
+// EncodeJSONRequest sends the request as JSON body
+func EncodeJSONRequest(ctx context.Context, r *resty.Request, request interface{}) error {
+	if request == nil {
+		return nil
+	}
+
+	r.SetHeader("Content-Type", "application/json")
+	r.SetBody(request)
+	return nil
+}
+
+// remoteSkipHeaders headers of the remote response that describe that connection or body,
+// cookies are kept apart by DecodeJSONResponse
+var remoteSkipHeaders = map[string]bool{
+	"Connection":          true,
+	"Keep-Alive":          true,
+	"Proxy-Authenticate":  true,
+	"Proxy-Authorization": true,
+	"Te":                  true,
+	"Trailer":             true,
+	"Transfer-Encoding":   true,
+	"Upgrade":             true,
+	"Content-Length":      true,
+	"Content-Type":        true,
+	"Content-Encoding":    true,
+	"Date":                true,
+	"Server":              true,
+	"Set-Cookie":          true,
+}
+
+// DecodeJSONResponse decodes the body into the value returned by newResponse, responses with
+// error status are decoded as a generic map so the caller can inspect the remote message.
+// The remote cookies and headers are kept, except the hop-by-hop and body headers, see
+// endpoint.MetadataResponse
+func DecodeJSONResponse(newResponse func() interface{}) DecodeResponseFunc {
+	return func(ctx context.Context, resp *resty.Response) (endpoint.EndpointResponse, error) {
+		if len(resp.Body()) == 0 {
+			return endpoint.ResponseWithMetadata(resp.StatusCode(), nil, remoteHeaders(resp.Header()), resp.Cookies()...), nil
+		}
+
+		var data interface{}
+		if resp.StatusCode() < http.StatusBadRequest {
+			data = newResponse()
+		} else {
+			data = &map[string]interface{}{}
+		}
+
+		if err := json.Unmarshal(resp.Body(), data); err != nil {
+			return nil, err
+		}
+
+		return endpoint.ResponseWithMetadata(resp.StatusCode(), data, remoteHeaders(resp.Header()), resp.Cookies()...), nil
+	}
+}
+
+// remoteHeaders copy of the headers without remoteSkipHeaders and the headers listed in Connection
+func remoteHeaders(header http.Header) http.Header {
+	hop := map[string]bool{}
+	for _, value := range header["Connection"] {
+		for _, name := range strings.Split(value, ",") {
+			hop[http.CanonicalHeaderKey(strings.TrimSpace(name))] = true
+		}
+	}
+
+	headers := http.Header{}
+	for key, values := range header {
+		key = http.CanonicalHeaderKey(key)
+		if remoteSkipHeaders[key] || hop[key] {
+			continue
+		}
+		headers[key] = append([]string(nil), values...)
+	}
+
+	return headers
+}
@@ -0,0 +1,80 @@
+package httptransport
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/helderfarias/go-api-kit/endpoint"
+	"github.com/helderfarias/go-api-kit/uri"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/resty.v1"
+)
+
+func TestClientEndpointCallRemoteRoute(t *testing.T) {
+	server := httptest.NewServer(NewServer(
+		func(ctx context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+			r, _ := RequestFromContext(ctx)
+			return endpoint.Response(201, map[string]string{"street": request.(*addressRequest).Street, "path": r.URL.Path}), nil
+		},
+		DecodeJSONRequest(func() interface{} { return &addressRequest{} }),
+		EncodeJSONResponse,
+	))
+	defer server.Close()
+
+	client := NewClient(
+		"POST",
+		uri.NewBuildURI(server.URL).Path("users/{id}/addresses"),
+		func(ctx context.Context, r *resty.Request, request interface{}) error {
+			r.SetPathParams(map[string]string{"id": "10"})
+			return EncodeJSONRequest(ctx, r, request)
+		},
+		DecodeJSONResponse(func() interface{} { return &map[string]string{} }),
+	)
+
+	resp, err := client.Endpoint()(nil, &addressRequest{Street: "Main"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 201, resp.Code())
+	assert.Equal(t, &map[string]string{"street": "Main", "path": "/users/10/addresses"}, resp.Data())
+}
+
+func TestClientEndpointErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(404)
+		w.Write([]byte(`{"message":"not found"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("GET", uri.NewBuildURI(server.URL), NopRequestEncoder, DecodeJSONResponse(func() interface{} { return &addressRequest{} }))
+
+	resp, err := client.Endpoint()(context.Background(), nil)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 404, resp.Code())
+	assert.Equal(t, &map[string]interface{}{"message": "not found"}, resp.Data())
+}
+
+func TestClientKeepsOnlyTheEndToEndHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "42")
+		w.Header().Set("X-Hop", "1")
+		w.Header().Set("Connection", "X-Hop")
+		w.Header().Set("Server", "remote")
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc"})
+		w.Write([]byte(`{"street":"Main"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("GET", uri.NewBuildURI(server.URL), NopRequestEncoder, DecodeJSONResponse(func() interface{} { return &addressRequest{} }))
+
+	resp, err := client.Endpoint()(context.Background(), nil)
+	assert.Nil(t, err)
+
+	rec := httptest.NewRecorder()
+	WriteMetadata(rec, resp)
+
+	assert.Equal(t, http.Header{"X-Request-Id": {"42"}}, endpoint.HeadersOf(resp))
+	assert.Equal(t, []string{"session=abc"}, rec.Header()["Set-Cookie"])
+}
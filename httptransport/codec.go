@@ -0,0 +1,321 @@
+package httptransport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/helderfarias/go-api-kit/apierror"
+	"github.com/helderfarias/go-api-kit/endpoint"
+	"github.com/vmihailenco/msgpack/v4"
+	"google.golang.org/protobuf/proto"
+)
+
+// ErrUnsupportedValue the codec can't represent the value, e.g. a map as CSV or a
+// struct that isn't a proto.Message as protobuf
+var ErrUnsupportedValue = errors.New("value not supported by the codec")
+
+// Codec encodes and decodes a media type
+type Codec interface {
+	// MediaTypes handled by the codec, the first one is written as Content-Type
+	MediaTypes() []string
+	Encode(w io.Writer, v interface{}) error
+	Decode(r io.Reader, v interface{}) error
+}
+
+// Codecs codecs available for negotiation, ordered by preference
+type Codecs []Codec
+
+// JSONCodec application/json
+type JSONCodec struct{}
+
+// XMLCodec application/xml
+type XMLCodec struct{}
+
+// MsgpackCodec application/msgpack, fields are named by the json tags
+type MsgpackCodec struct{}
+
+// ProtobufCodec application/x-protobuf, values must be proto.Message
+type ProtobufCodec struct{}
+
+// DefaultCodecs JSON first, used when the request has no Accept or Content-Type
+var DefaultCodecs = Codecs{JSONCodec{}, XMLCodec{}, CSVCodec{}, MsgpackCodec{}, ProtobufCodec{}}
+
+type acceptRange struct {
+	mediaType string
+	q         float64
+}
+
+func (JSONCodec) MediaTypes() []string {
+	return []string{"application/json; charset=utf-8"}
+}
+
+func (JSONCodec) Encode(w io.Writer, v interface{}) error {
+	return json.NewEncoder(w).Encode(v)
+}
+
+func (JSONCodec) Decode(r io.Reader, v interface{}) error {
+	return json.NewDecoder(r).Decode(v)
+}
+
+func (XMLCodec) MediaTypes() []string {
+	return []string{"application/xml; charset=utf-8", "text/xml"}
+}
+
+func (XMLCodec) Encode(w io.Writer, v interface{}) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	err := xml.NewEncoder(w).Encode(v)
+	if _, ok := err.(*xml.UnsupportedTypeError); ok {
+		return ErrUnsupportedValue
+	}
+	return err
+}
+
+func (XMLCodec) Decode(r io.Reader, v interface{}) error {
+	return xml.NewDecoder(r).Decode(v)
+}
+
+func (MsgpackCodec) MediaTypes() []string {
+	return []string{"application/msgpack", "application/x-msgpack"}
+}
+
+func (MsgpackCodec) Encode(w io.Writer, v interface{}) error {
+	return msgpack.NewEncoder(w).UseJSONTag(true).Encode(v)
+}
+
+func (MsgpackCodec) Decode(r io.Reader, v interface{}) error {
+	return msgpack.NewDecoder(r).UseJSONTag(true).Decode(v)
+}
+
+func (ProtobufCodec) MediaTypes() []string {
+	return []string{"application/x-protobuf", "application/protobuf"}
+}
+
+func (ProtobufCodec) Encode(w io.Writer, v interface{}) error {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return ErrUnsupportedValue
+	}
+
+	data, err := proto.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(data)
+	return err
+}
+
+func (ProtobufCodec) Decode(r io.Reader, v interface{}) error {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return ErrUnsupportedValue
+	}
+
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	return proto.Unmarshal(data, msg)
+}
+
+// Negotiate the codecs acceptable by the Accept header, ordered by quality and then by
+// the codecs preference. An empty header accepts every codec.
+func (c Codecs) Negotiate(accept string) Codecs {
+	if strings.TrimSpace(accept) == "" {
+		return c
+	}
+
+	ranges := parseAccept(accept)
+
+	type candidate struct {
+		codec Codec
+		q     float64
+		order int
+	}
+
+	candidates := []candidate{}
+	for i, codec := range c {
+		q, ok := codecQuality(codec, ranges)
+		if ok && q > 0 {
+			candidates = append(candidates, candidate{codec: codec, q: q, order: i})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].q != candidates[j].q {
+			return candidates[i].q > candidates[j].q
+		}
+		return candidates[i].order < candidates[j].order
+	})
+
+	acceptable := Codecs{}
+	for _, candidate := range candidates {
+		acceptable = append(acceptable, candidate.codec)
+	}
+
+	return acceptable
+}
+
+// NegotiatedMediaType media type of the first codec acceptable by the Accept header of the
+// request, empty when none is, e.g. for middleware.ConditionalOptions
+func (c Codecs) NegotiatedMediaType(r *http.Request) string {
+	acceptable := c.Negotiate(r.Header.Get("Accept"))
+	if len(acceptable) == 0 {
+		return ""
+	}
+	return baseMediaType(acceptable[0].MediaTypes()[0])
+}
+
+// ForContentType the codec handling the Content-Type header
+func (c Codecs) ForContentType(contentType string) (Codec, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, false
+	}
+
+	for _, codec := range c {
+		for _, t := range codec.MediaTypes() {
+			if baseMediaType(t) == mediaType {
+				return codec, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// EncodeResponse writes Data with the codec chosen by the Accept header, like EncodeJSONResponse
+// does for JSON. The first acceptable codec able to represent the data is used, successful
+// responses no codec can represent get 406 and error responses fall back to JSON.
+func EncodeResponse(codecs Codecs) EncodeResponseFunc {
+	return func(ctx context.Context, w http.ResponseWriter, resp endpoint.EndpointResponse) error {
+		if resp == nil || resp.Data() == nil {
+			return EncodeJSONResponse(ctx, w, resp)
+		}
+
+		if _, ok := resp.Data().(apierror.Problem); ok {
+			return EncodeJSONResponse(ctx, w, resp)
+		}
+
+		accept := ""
+		if r, ok := RequestFromContext(ctx); ok {
+			accept = r.Header.Get("Accept")
+		}
+
+		w.Header().Add("Vary", "Accept")
+
+		for _, codec := range codecs.Negotiate(accept) {
+			buf := &bytes.Buffer{}
+			err := codec.Encode(buf, resp.Data())
+			if err == ErrUnsupportedValue {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+
+			return writeResponse(ctx, w, resp, codec.MediaTypes()[0], buf.Bytes())
+		}
+
+		if resp.Code() >= http.StatusBadRequest {
+			return EncodeJSONResponse(ctx, w, resp)
+		}
+
+		return apierror.New(http.StatusNotAcceptable, "not_acceptable", "no acceptable representation for "+accept)
+	}
+}
+
+// DecodeRequest decodes the body into the value returned by newRequest with the codec of the
+// Content-Type header (the first codec when missing), unknown types are rejected with 415
+func DecodeRequest(codecs Codecs, newRequest func() interface{}) DecodeRequestFunc {
+	return func(ctx context.Context, r *http.Request) (interface{}, error) {
+		request := newRequest()
+
+		if r.Body == nil || r.ContentLength == 0 || len(codecs) == 0 {
+			return request, nil
+		}
+
+		codec := codecs[0]
+		if contentType := r.Header.Get("Content-Type"); contentType != "" {
+			c, ok := codecs.ForContentType(contentType)
+			if !ok {
+				return nil, apierror.New(http.StatusUnsupportedMediaType, "unsupported_media_type", "unsupported content type "+contentType)
+			}
+			codec = c
+		}
+
+		if err := codec.Decode(r.Body, request); err != nil {
+			return nil, apierror.Wrap(err, http.StatusBadRequest, "bad_request", "invalid request body")
+		}
+
+		return request, nil
+	}
+}
+
+func parseAccept(accept string) []acceptRange {
+	ranges := []acceptRange{}
+
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+
+		ranges = append(ranges, acceptRange{mediaType: mediaType, q: q})
+	}
+
+	return ranges
+}
+
+// codecQuality the quality of the most specific range matching one of the codec media types
+func codecQuality(codec Codec, ranges []acceptRange) (float64, bool) {
+	best, specificity := 0.0, -1
+
+	for _, t := range codec.MediaTypes() {
+		mediaType := baseMediaType(t)
+		main := strings.SplitN(mediaType, "/", 2)[0]
+
+		for _, r := range ranges {
+			s := -1
+			switch {
+			case r.mediaType == mediaType:
+				s = 2
+			case r.mediaType == main+"/*":
+				s = 1
+			case r.mediaType == "*/*":
+				s = 0
+			}
+
+			if s > specificity || (s == specificity && s >= 0 && r.q > best) {
+				best, specificity = r.q, s
+			}
+		}
+	}
+
+	return best, specificity >= 0
+}
+
+func baseMediaType(contentType string) string {
+	return strings.TrimSpace(strings.SplitN(contentType, ";", 2)[0])
+}
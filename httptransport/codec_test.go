@@ -0,0 +1,120 @@
+package httptransport
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/helderfarias/go-api-kit/endpoint"
+	"github.com/stretchr/testify/assert"
+	"github.com/vmihailenco/msgpack/v4"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type exportRow struct {
+	ID      int64     `json:"id"`
+	Street  string    `json:"street" xml:"street"`
+	Number  *int      `csv:"number"`
+	Created time.Time `json:"created"`
+	Secret  string    `json:"-"`
+}
+
+func serveNegotiated(data interface{}, accept string) *httptest.ResponseRecorder {
+	handler := NewServer(
+		func(ctx context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+			return endpoint.Response(200, data), nil
+		},
+		NopRequestDecoder,
+		EncodeResponse(DefaultCodecs),
+	)
+
+	r := httptest.NewRequest("GET", "/addresses", nil)
+	r.Header.Set("Accept", accept)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, r)
+	return rec
+}
+
+func TestNegotiateByQualityAndSpecificity(t *testing.T) {
+	assert.Equal(t, Codecs{XMLCodec{}, JSONCodec{}}, Codecs{JSONCodec{}, XMLCodec{}}.Negotiate("application/json;q=0.5, text/xml"))
+	assert.Equal(t, Codecs{JSONCodec{}, XMLCodec{}}, Codecs{JSONCodec{}, XMLCodec{}}.Negotiate("*/*"))
+	assert.Equal(t, Codecs{XMLCodec{}}, Codecs{JSONCodec{}, XMLCodec{}}.Negotiate("*/*, application/json;q=0"))
+	assert.Equal(t, Codecs{}, Codecs{JSONCodec{}, XMLCodec{}}.Negotiate("image/png"))
+
+	r := httptest.NewRequest("GET", "/", nil)
+	assert.Equal(t, "application/json", DefaultCodecs.NegotiatedMediaType(r))
+	r.Header.Set("Accept", "text/xml")
+	assert.Equal(t, "application/xml", DefaultCodecs.NegotiatedMediaType(r))
+}
+
+func TestEncodeResponseAsCSV(t *testing.T) {
+	number := 10
+	created := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	rows := []exportRow{{ID: 1, Street: "Main, 1", Number: &number, Created: created, Secret: "x"}, {ID: 2, Street: "Second", Created: created}}
+
+	rec := serveNegotiated(endpoint.Paginate(rows, 1, 10, 2), "text/csv")
+
+	assert.Equal(t, 200, rec.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "Accept", rec.Header().Get("Vary"))
+	assert.Equal(t, "id,street,number,created\n1,\"Main, 1\",10,2020-01-02T03:04:05Z\n2,Second,,2020-01-02T03:04:05Z\n", rec.Body.String())
+
+	decoded := []*exportRow{}
+	assert.Nil(t, CSVCodec{}.Decode(strings.NewReader(rec.Body.String()), &decoded))
+	assert.Equal(t, []*exportRow{{ID: 1, Street: "Main, 1", Number: &number, Created: created}, {ID: 2, Street: "Second", Created: created}}, decoded)
+}
+
+func TestEncodeResponseFallsBackToNextAcceptableCodec(t *testing.T) {
+	rec := serveNegotiated(map[string]string{"street": "Main"}, "text/csv, application/json;q=0.1")
+
+	assert.Equal(t, 200, rec.Code)
+	assert.Equal(t, "application/json; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"street":"Main"}`, rec.Body.String())
+}
+
+func TestEncodeResponseNotAcceptable(t *testing.T) {
+	rec := serveNegotiated(map[string]string{"street": "Main"}, "application/x-protobuf")
+
+	assert.Equal(t, 406, rec.Code)
+	assert.Contains(t, rec.Body.String(), "not_acceptable")
+}
+
+func TestEncodeResponseAsXMLMsgpackAndProtobuf(t *testing.T) {
+	rec := serveNegotiated(exportRow{ID: 1, Street: "Main"}, "application/xml")
+	assert.Equal(t, "application/xml; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Body.String(), "<street>Main</street>")
+
+	rec = serveNegotiated(exportRow{ID: 1, Street: "Main"}, "application/msgpack")
+	decoded := map[string]interface{}{}
+	assert.Nil(t, msgpack.Unmarshal(rec.Body.Bytes(), &decoded))
+	assert.Equal(t, "Main", decoded["street"])
+
+	rec = serveNegotiated(wrapperspb.String("Main"), "application/x-protobuf")
+	msg := &wrapperspb.StringValue{}
+	assert.Nil(t, proto.Unmarshal(rec.Body.Bytes(), msg))
+	assert.Equal(t, "Main", msg.Value)
+}
+
+func TestDecodeRequestByContentType(t *testing.T) {
+	dec := DecodeRequest(DefaultCodecs, func() interface{} { return &addressRequest{} })
+
+	body, _ := msgpack.Marshal(map[string]string{"street": "Main"})
+	r := httptest.NewRequest("POST", "/addresses", bytes.NewReader(body))
+	r.Header.Set("Content-Type", "application/msgpack")
+
+	request, err := dec(context.Background(), r)
+	assert.Nil(t, err)
+	assert.Equal(t, "Main", request.(*addressRequest).Street)
+
+	r = httptest.NewRequest("POST", "/addresses", strings.NewReader("street=Main"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	_, err = dec(context.Background(), r)
+	assert.Equal(t, http.StatusUnsupportedMediaType, err.(StatusCoder).StatusCode())
+}
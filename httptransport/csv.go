@@ -0,0 +1,286 @@
+package httptransport
+
+import (
+	"encoding"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/helderfarias/go-api-kit/endpoint"
+)
+
+// CSVCodec text/csv for slices of structs (export endpoints), a single struct is written as one
+// row and paged data (endpoint.EntityPaging) writes only the rows. Columns are named by the csv
+// tag, then the json tag, then the field name; "-" skips the field.
+type CSVCodec struct {
+	// Comma field delimiter, defaults to ','
+	Comma rune
+}
+
+type csvColumn struct {
+	name  string
+	index int
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func (CSVCodec) MediaTypes() []string {
+	return []string{"text/csv; charset=utf-8"}
+}
+
+func (c CSVCodec) Encode(w io.Writer, v interface{}) error {
+	switch paged := v.(type) {
+	case endpoint.EntityPaging:
+		v = paged.Data
+	case endpoint.EntityCursorPaging:
+		v = paged.Data
+	}
+
+	rows := reflect.Indirect(reflect.ValueOf(v))
+	if rows.Kind() == reflect.Struct {
+		rows = reflect.Append(reflect.MakeSlice(reflect.SliceOf(rows.Type()), 0, 1), rows)
+	}
+
+	if rows.Kind() != reflect.Slice && rows.Kind() != reflect.Array {
+		return ErrUnsupportedValue
+	}
+
+	elem := rows.Type().Elem()
+	if elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+	if elem.Kind() != reflect.Struct {
+		return ErrUnsupportedValue
+	}
+
+	columns := csvColumns(elem)
+
+	writer := csv.NewWriter(w)
+	if c.Comma != 0 {
+		writer.Comma = c.Comma
+	}
+
+	header := []string{}
+	for _, col := range columns {
+		header = append(header, col.name)
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for i := 0; i < rows.Len(); i++ {
+		row := reflect.Indirect(rows.Index(i))
+
+		record := make([]string, len(columns))
+		if row.IsValid() {
+			for j, col := range columns {
+				value, err := formatCSV(row.Field(col.index))
+				if err != nil {
+					return err
+				}
+				record[j] = value
+			}
+		}
+
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// Decode reads the rows into a pointer to a slice of structs, matching the header with the
+// column names, unknown columns are ignored
+func (c CSVCodec) Decode(r io.Reader, v interface{}) error {
+	ptr := reflect.ValueOf(v)
+	if ptr.Kind() != reflect.Ptr || ptr.Elem().Kind() != reflect.Slice {
+		return ErrUnsupportedValue
+	}
+
+	slice := ptr.Elem()
+	elem := slice.Type().Elem()
+	isPtr := elem.Kind() == reflect.Ptr
+	if isPtr {
+		elem = elem.Elem()
+	}
+	if elem.Kind() != reflect.Struct {
+		return ErrUnsupportedValue
+	}
+
+	reader := csv.NewReader(r)
+	if c.Comma != 0 {
+		reader.Comma = c.Comma
+	}
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		return nil
+	}
+
+	byName := map[string]int{}
+	for _, col := range csvColumns(elem) {
+		byName[col.name] = col.index
+	}
+
+	for line, record := range records[1:] {
+		row := reflect.New(elem).Elem()
+
+		for i, name := range records[0] {
+			index, ok := byName[strings.TrimSpace(name)]
+			if !ok || i >= len(record) {
+				continue
+			}
+
+			if err := parseCSV(row.Field(index), record[i]); err != nil {
+				return fmt.Errorf("csv line %v, column %v: %v", line+2, name, err)
+			}
+		}
+
+		if isPtr {
+			row = row.Addr()
+		}
+		slice = reflect.Append(slice, row)
+	}
+
+	ptr.Elem().Set(slice)
+	return nil
+}
+
+func csvColumns(t reflect.Type) []csvColumn {
+	columns := []csvColumn{}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name := field.Name
+		if tag, ok := field.Tag.Lookup("csv"); ok {
+			name = strings.Split(tag, ",")[0]
+		} else if tag, ok := field.Tag.Lookup("json"); ok && strings.Split(tag, ",")[0] != "" {
+			name = strings.Split(tag, ",")[0]
+		}
+
+		if name == "-" {
+			continue
+		}
+
+		columns = append(columns, csvColumn{name: name, index: i})
+	}
+
+	return columns
+}
+
+func formatCSV(v reflect.Value) (string, error) {
+	if v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return "", nil
+		}
+		v = v.Elem()
+	}
+
+	if v.Type() == timeType {
+		return v.Interface().(time.Time).Format(time.RFC3339), nil
+	}
+
+	if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+		text, err := m.MarshalText()
+		return string(text), err
+	}
+
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		values := []string{}
+		for i := 0; i < v.Len(); i++ {
+			s, err := formatCSV(v.Index(i))
+			if err != nil {
+				return "", err
+			}
+			values = append(values, s)
+		}
+		return strings.Join(values, ";"), nil
+	case reflect.Map:
+		keys := []string{}
+		for _, k := range v.MapKeys() {
+			keys = append(keys, fmt.Sprintf("%v=%v", k.Interface(), v.MapIndex(k).Interface()))
+		}
+		sort.Strings(keys)
+		return strings.Join(keys, ";"), nil
+	}
+
+	return fmt.Sprint(v.Interface()), nil
+}
+
+func parseCSV(field reflect.Value, value string) error {
+	if field.Kind() == reflect.Ptr {
+		if value == "" {
+			return nil
+		}
+		field.Set(reflect.New(field.Type().Elem()))
+		field = field.Elem()
+	}
+
+	if field.Type() == timeType {
+		if value == "" {
+			return nil
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	if u, ok := field.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(value))
+	}
+
+	if value == "" {
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(n)
+	default:
+		return fmt.Errorf("unsupported field type %v", field.Type())
+	}
+
+	return nil
+}
@@ -0,0 +1,248 @@
+package httptransport
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/helderfarias/go-api-kit/apierror"
+	"github.com/helderfarias/go-api-kit/auth"
+	"github.com/helderfarias/go-api-kit/endpoint"
+	"github.com/sirupsen/logrus"
+)
+
+// DecodeRequestFunc extracts the endpoint request from the HTTP request
+type DecodeRequestFunc func(ctx context.Context, r *http.Request) (interface{}, error)
+
+// EncodeResponseFunc writes the endpoint response to the HTTP response
+type EncodeResponseFunc func(ctx context.Context, w http.ResponseWriter, resp endpoint.EndpointResponse) error
+
+// ErrorEncoder writes errors returned by the decoder, the endpoint or the encoder
+type ErrorEncoder func(ctx context.Context, err error, w http.ResponseWriter)
+
+// RequestFunc runs before decoding, may enrich the context from the request
+type RequestFunc func(ctx context.Context, r *http.Request) context.Context
+
+// ServerResponseFunc runs after the endpoint and before encoding, may write headers
+type ServerResponseFunc func(ctx context.Context, w http.ResponseWriter, resp endpoint.EndpointResponse) context.Context
+
+// StatusCoder errors carrying the HTTP status used by the DefaultErrorEncoder
+type StatusCoder = apierror.StatusCoder
+
+// Server wraps an endpoint and implements http.Handler
+type Server struct {
+	e            endpoint.Endpoint
+	dec          DecodeRequestFunc
+	enc          EncodeResponseFunc
+	before       []RequestFunc
+	after        []ServerResponseFunc
+	errorEncoder ErrorEncoder
+}
+
+// ServerOption sets an optional parameter for servers
+type ServerOption func(s *Server)
+
+// skipHeaders framing headers written by net/http, never copied from responses
+var skipHeaders = map[string]bool{
+	"Content-Length":    true,
+	"Transfer-Encoding": true,
+	"Connection":        true,
+}
+
+// NewServer constructs a new server, which implements http.Handler and wraps the endpoint
+func NewServer(e endpoint.Endpoint, dec DecodeRequestFunc, enc EncodeResponseFunc, options ...ServerOption) *Server {
+	s := &Server{
+		e:            e,
+		dec:          dec,
+		enc:          enc,
+		errorEncoder: DefaultErrorEncoder,
+	}
+
+	for _, o := range options {
+		o(s)
+	}
+
+	return s
+}
+
+// ServerBefore functions executed on the HTTP request before the request is decoded
+func ServerBefore(before ...RequestFunc) ServerOption {
+	return func(s *Server) {
+		s.before = append(s.before, before...)
+	}
+}
+
+// ServerAfter functions executed on the HTTP response writer after the endpoint is invoked
+func ServerAfter(after ...ServerResponseFunc) ServerOption {
+	return func(s *Server) {
+		s.after = append(s.after, after...)
+	}
+}
+
+// ServerErrorEncoder used to encode errors to the http.ResponseWriter
+func ServerErrorEncoder(ee ErrorEncoder) ServerOption {
+	return func(s *Server) {
+		s.errorEncoder = ee
+	}
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := endpoint.ContextWithRequest(r.Context(), r)
+
+	for _, f := range s.before {
+		ctx = f(ctx, r)
+	}
+
+	request, err := s.dec(ctx, r)
+	if err != nil {
+		logrus.WithField("httptransport.decode", r.URL.Path).Debug(err)
+		s.errorEncoder(ctx, err, w)
+		return
+	}
+
+	resp, err := s.e(ctx, request)
+	if err != nil {
+		logrus.WithField("httptransport.endpoint", r.URL.Path).Debug(err)
+		s.errorEncoder(ctx, err, w)
+		return
+	}
+
+	for _, f := range s.after {
+		ctx = f(ctx, w, resp)
+	}
+
+	if err := s.enc(ctx, w, resp); err != nil {
+		logrus.WithField("httptransport.encode", r.URL.Path).Debug(err)
+		s.errorEncoder(ctx, err, w)
+		return
+	}
+}
+
+// RequestFromContext the *http.Request injected by the server, see endpoint.RequestFromContext
+func RequestFromContext(ctx context.Context) (*http.Request, bool) {
+	return endpoint.RequestFromContext(ctx)
+}
+
+// PopulateAuthToken stores the Authorization bearer token for the JWT middleware
+func PopulateAuthToken(ctx context.Context, r *http.Request) context.Context {
+	token := r.Header.Get("Authorization")
+	if token == "" {
+		return ctx
+	}
+
+	return auth.ContextWithToken(ctx, token)
+}
+
+// NopRequestDecoder decodes nothing, for endpoints without request
+func NopRequestDecoder(ctx context.Context, r *http.Request) (interface{}, error) {
+	return nil, nil
+}
+
+// DecodeJSONRequest decodes the body into the value returned by newRequest
+func DecodeJSONRequest(newRequest func() interface{}) DecodeRequestFunc {
+	return func(ctx context.Context, r *http.Request) (interface{}, error) {
+		request := newRequest()
+
+		if r.Body == nil || r.ContentLength == 0 {
+			return request, nil
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+			return nil, apierror.Wrap(err, http.StatusBadRequest, "bad_request", "invalid JSON body")
+		}
+
+		return request, nil
+	}
+}
+
+// EncodeJSONResponse uses EndpointResponse.Code as status and writes Data as JSON,
+// responses without data are written as 204 (or the given code) with an empty body,
+// 304 responses and HEAD requests never have a body
+func EncodeJSONResponse(ctx context.Context, w http.ResponseWriter, resp endpoint.EndpointResponse) error {
+	if resp == nil || resp.Data() == nil {
+		return writeResponse(ctx, w, resp, "", nil)
+	}
+
+	contentType := "application/json; charset=utf-8"
+	if _, ok := resp.Data().(apierror.Problem); ok {
+		contentType = apierror.ProblemContentType
+	}
+
+	body, err := json.Marshal(resp.Data())
+	if err != nil {
+		return err
+	}
+
+	return writeResponse(ctx, w, resp, contentType, append(body, '\n'))
+}
+
+// writeResponse writes the metadata, the status and the encoded body
+func writeResponse(ctx context.Context, w http.ResponseWriter, resp endpoint.EndpointResponse, contentType string, body []byte) error {
+	if resp == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+
+	code := resp.Code()
+	if code == 0 {
+		code = http.StatusOK
+	}
+
+	WriteMetadata(w, resp)
+
+	r, _ := RequestFromContext(ctx)
+	if code == http.StatusNotModified || (r != nil && r.Method == http.MethodHead) {
+		w.WriteHeader(code)
+		return nil
+	}
+
+	if resp.Data() == nil {
+		if code == http.StatusOK {
+			code = http.StatusNoContent
+		}
+		w.WriteHeader(code)
+		return nil
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(code)
+	_, err := w.Write(body)
+	return err
+}
+
+// WriteMetadata writes the headers and cookies of the response, encoders call it before writing the status
+func WriteMetadata(w http.ResponseWriter, resp endpoint.EndpointResponse) {
+	for key, values := range endpoint.HeadersOf(resp) {
+		if skipHeaders[http.CanonicalHeaderKey(key)] {
+			continue
+		}
+
+		for _, v := range values {
+			w.Header().Add(key, v)
+		}
+	}
+
+	for _, cookie := range endpoint.CookiesOf(resp) {
+		http.SetCookie(w, cookie)
+	}
+}
+
+// DefaultErrorEncoder writes *apierror.Error as problem details (RFC 7807) and other
+// errors as JSON with the message of apierror.Public.
+func DefaultErrorEncoder(ctx context.Context, err error, w http.ResponseWriter) {
+	if _, ok := apierror.As(err); ok {
+		if r, ok := RequestFromContext(ctx); ok {
+			ctx = apierror.ContextWithInstance(ctx, r.URL.Path)
+		}
+		apierror.EncodeProblem(ctx, err, w)
+		return
+	}
+
+	e := apierror.Public(err)
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(e.StatusCode())
+	json.NewEncoder(w).Encode(map[string]string{"message": strings.TrimSpace(e.Message)})
+}
@@ -0,0 +1,118 @@
+package httptransport
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/helderfarias/go-api-kit/apierror"
+	"github.com/helderfarias/go-api-kit/auth"
+	"github.com/helderfarias/go-api-kit/endpoint"
+	"github.com/stretchr/testify/assert"
+)
+
+type addressRequest struct {
+	Street string `json:"street"`
+}
+
+func TestServerEncodeResponseCodeAndData(t *testing.T) {
+	handler := NewServer(
+		func(ctx context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+			r, _ := RequestFromContext(ctx)
+			return endpoint.Response(201, map[string]string{"street": request.(*addressRequest).Street, "method": r.Method}), nil
+		},
+		DecodeJSONRequest(func() interface{} { return &addressRequest{} }),
+		EncodeJSONResponse,
+		ServerAfter(func(ctx context.Context, w http.ResponseWriter, resp endpoint.EndpointResponse) context.Context {
+			w.Header().Set("X-After", "true")
+			return ctx
+		}),
+	)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("POST", "/addresses", strings.NewReader(`{"street":"Main"}`)))
+
+	assert.Equal(t, 201, rec.Code)
+	assert.Equal(t, "true", rec.Header().Get("X-After"))
+	assert.JSONEq(t, `{"street":"Main","method":"POST"}`, rec.Body.String())
+}
+
+func TestServerErrorEncoder(t *testing.T) {
+	handler := NewServer(
+		func(ctx context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+			return nil, errors.New("service error")
+		},
+		NopRequestDecoder,
+		EncodeJSONResponse,
+	)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/addresses", nil))
+
+	assert.Equal(t, 500, rec.Code)
+	assert.JSONEq(t, `{"message":"Internal Server Error"}`, rec.Body.String())
+}
+
+func TestServerBadRequestOnInvalidJSON(t *testing.T) {
+	handler := NewServer(endpoint.Nop, DecodeJSONRequest(func() interface{} { return &addressRequest{} }), EncodeJSONResponse)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("POST", "/addresses", strings.NewReader(`{`)))
+
+	assert.Equal(t, 400, rec.Code)
+}
+
+func TestServerPopulateAuthToken(t *testing.T) {
+	handler := NewServer(
+		func(ctx context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+			return endpoint.Response(200, auth.TokenFromContext(ctx)), nil
+		},
+		NopRequestDecoder,
+		EncodeJSONResponse,
+		ServerBefore(PopulateAuthToken),
+	)
+
+	req := httptest.NewRequest("GET", "/addresses", nil)
+	req.Header.Set("Authorization", "Bearer abc")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.JSONEq(t, `"Bearer abc"`, rec.Body.String())
+}
+
+func TestServerEncodeAPIErrorAsProblem(t *testing.T) {
+	handler := NewServer(
+		func(ctx context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+			return nil, apierror.Conflict("address already exists")
+		},
+		NopRequestDecoder,
+		EncodeJSONResponse,
+	)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("POST", "/addresses", nil))
+
+	assert.Equal(t, 409, rec.Code)
+	assert.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"type":"about:blank","title":"Conflict","status":409,"detail":"address already exists","instance":"/addresses","code":"conflict"}`, rec.Body.String())
+}
+
+func TestServerWriteHeadersAndCookies(t *testing.T) {
+	handler := NewServer(
+		func(ctx context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+			return endpoint.WithCookie(endpoint.Created("/addresses/1", "ok"), &http.Cookie{Name: "session", Value: "1"}), nil
+		},
+		NopRequestDecoder,
+		EncodeJSONResponse,
+	)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("POST", "/addresses", nil))
+
+	assert.Equal(t, 201, rec.Code)
+	assert.Equal(t, "/addresses/1", rec.Header().Get("Location"))
+	assert.Equal(t, "session=1", rec.Header().Get("Set-Cookie"))
+}
@@ -0,0 +1,272 @@
+package httptransport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/helderfarias/go-api-kit/apierror"
+	"github.com/helderfarias/go-api-kit/endpoint"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// EventStreamContentType Server-Sent Events
+	EventStreamContentType = "text/event-stream"
+	// NDJSONContentType newline delimited JSON
+	NDJSONContentType = "application/x-ndjson"
+)
+
+// StreamOption sets an optional parameter for stream encoders
+type StreamOption func(c *streamConfig)
+
+type streamConfig struct {
+	heartbeat time.Duration
+	retry     time.Duration
+}
+
+// streamWriter serializes the writes of the stream and of the heartbeats
+type streamWriter struct {
+	mu      sync.Mutex
+	w       http.ResponseWriter
+	flusher http.Flusher
+}
+
+// StreamHeartbeat interval of the comment lines that keep idle SSE connections open
+// through proxies, defaults to 15s, zero disables them
+func StreamHeartbeat(interval time.Duration) StreamOption {
+	return func(c *streamConfig) {
+		c.heartbeat = interval
+	}
+}
+
+// StreamRetry reconnection time sent to SSE clients when the stream starts
+func StreamRetry(retry time.Duration) StreamOption {
+	return func(c *streamConfig) {
+		c.retry = retry
+	}
+}
+
+// EncodeSSEResponse writes streaming responses (see endpoint.Stream) as Server-Sent Events, the
+// Last-Event-ID header is passed to the stream to resume it. Other responses are written as JSON.
+func EncodeSSEResponse(options ...StreamOption) EncodeResponseFunc {
+	c := &streamConfig{heartbeat: 15 * time.Second}
+	for _, o := range options {
+		o(c)
+	}
+
+	return func(ctx context.Context, w http.ResponseWriter, resp endpoint.EndpointResponse) error {
+		stream, ok := endpoint.StreamOf(resp)
+		if !ok {
+			return EncodeJSONResponse(ctx, w, resp)
+		}
+
+		lastEventID := ""
+		if r, ok := RequestFromContext(ctx); ok {
+			lastEventID = r.Header.Get("Last-Event-ID")
+		}
+
+		sw := startStream(w, resp, EventStreamContentType)
+
+		if c.retry > 0 {
+			sw.write(fmt.Sprintf("retry: %d\n\n", c.retry/time.Millisecond))
+		}
+
+		ctx, cancel := context.WithCancel(contextOrBackground(ctx))
+		defer cancel()
+
+		var wg sync.WaitGroup
+		if c.heartbeat > 0 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				ticker := time.NewTicker(c.heartbeat)
+				defer ticker.Stop()
+
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case <-ticker.C:
+						if err := sw.write(": heartbeat\n\n"); err != nil {
+							cancel()
+							return
+						}
+					}
+				}
+			}()
+		}
+
+		err := stream(ctx, lastEventID, func(event endpoint.Event) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			msg, err := formatEvent(event)
+			if err != nil {
+				return err
+			}
+
+			return sw.write(msg)
+		})
+
+		if err != nil && ctx.Err() == nil {
+			logrus.WithField("httptransport.stream", "sse").Debug(err)
+			msg, _ := formatEvent(endpoint.Event{Name: "error", Data: map[string]string{"message": apierror.Public(err).Message}})
+			sw.write(msg)
+		}
+
+		cancel()
+		wg.Wait()
+		return nil
+	}
+}
+
+// EncodeNDJSONResponse writes streaming responses as newline delimited JSON, one line per event
+// Data, flushing each line. Other responses are written as JSON.
+func EncodeNDJSONResponse(ctx context.Context, w http.ResponseWriter, resp endpoint.EndpointResponse) error {
+	stream, ok := endpoint.StreamOf(resp)
+	if !ok {
+		return EncodeJSONResponse(ctx, w, resp)
+	}
+
+	sw := startStream(w, resp, NDJSONContentType)
+
+	ctx, cancel := context.WithCancel(contextOrBackground(ctx))
+	defer cancel()
+
+	err := stream(ctx, "", func(event endpoint.Event) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		line, err := json.Marshal(event.Data)
+		if err != nil {
+			return err
+		}
+
+		return sw.write(string(line) + "\n")
+	})
+
+	if err != nil && ctx.Err() == nil {
+		logrus.WithField("httptransport.stream", "ndjson").Debug(err)
+		line, _ := json.Marshal(map[string]string{"error": apierror.Public(err).Message})
+		sw.write(string(line) + "\n")
+	}
+
+	return nil
+}
+
+// EncodeStreamResponse writes streaming responses as SSE when the client accepts
+// text/event-stream and as NDJSON otherwise, other responses are written by fallback
+func EncodeStreamResponse(fallback EncodeResponseFunc, options ...StreamOption) EncodeResponseFunc {
+	sse := EncodeSSEResponse(options...)
+
+	return func(ctx context.Context, w http.ResponseWriter, resp endpoint.EndpointResponse) error {
+		if _, ok := endpoint.StreamOf(resp); !ok {
+			return fallback(ctx, w, resp)
+		}
+
+		w.Header().Add("Vary", "Accept")
+
+		if r, ok := RequestFromContext(ctx); ok && strings.Contains(r.Header.Get("Accept"), EventStreamContentType) {
+			return sse(ctx, w, resp)
+		}
+
+		return EncodeNDJSONResponse(ctx, w, resp)
+	}
+}
+
+func startStream(w http.ResponseWriter, resp endpoint.EndpointResponse, contentType string) *streamWriter {
+	code := resp.Code()
+	if code == 0 {
+		code = http.StatusOK
+	}
+
+	WriteMetadata(w, resp)
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(code)
+
+	sw := &streamWriter{w: w}
+	sw.flusher, _ = w.(http.Flusher)
+	sw.flush()
+
+	return sw
+}
+
+func (s *streamWriter) write(msg string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := io.WriteString(s.w, msg); err != nil {
+		return err
+	}
+
+	s.flush()
+	return nil
+}
+
+func (s *streamWriter) flush() {
+	if s.flusher != nil {
+		s.flusher.Flush()
+	}
+}
+
+// formatEvent SSE message, string data is sent as is and other values as JSON,
+// multi-line data is split in several data fields
+func formatEvent(event endpoint.Event) (string, error) {
+	buf := &bytes.Buffer{}
+
+	if event.ID != "" {
+		fmt.Fprintf(buf, "id: %v\n", singleLine(event.ID))
+	}
+	if event.Name != "" {
+		fmt.Fprintf(buf, "event: %v\n", singleLine(event.Name))
+	}
+	if event.Retry > 0 {
+		fmt.Fprintf(buf, "retry: %d\n", event.Retry/time.Millisecond)
+	}
+
+	var data string
+	switch v := event.Data.(type) {
+	case nil:
+	case string:
+		data = v
+	case []byte:
+		data = string(v)
+	default:
+		enc, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		data = string(enc)
+	}
+
+	data = strings.NewReplacer("\r\n", "\n", "\r", "\n").Replace(data)
+	for _, line := range strings.Split(data, "\n") {
+		fmt.Fprintf(buf, "data: %v\n", line)
+	}
+
+	buf.WriteString("\n")
+	return buf.String(), nil
+}
+
+func singleLine(value string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(value)
+}
+
+func contextOrBackground(ctx context.Context) context.Context {
+	if ctx == nil {
+		return context.Background()
+	}
+	return ctx
+}
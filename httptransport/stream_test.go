@@ -0,0 +1,91 @@
+package httptransport
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/helderfarias/go-api-kit/endpoint"
+	"github.com/stretchr/testify/assert"
+)
+
+func serveStream(resp endpoint.EndpointResponse, enc EncodeResponseFunc, headers map[string]string) *httptest.ResponseRecorder {
+	handler := NewServer(
+		func(ctx context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+			return resp, nil
+		},
+		NopRequestDecoder,
+		enc,
+	)
+
+	r := httptest.NewRequest("GET", "/events", nil)
+	for k, v := range headers {
+		r.Header.Set(k, v)
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, r)
+	return rec
+}
+
+func TestEncodeSSEResponseResumesFromLastEventID(t *testing.T) {
+	resp := endpoint.Stream(200, func(ctx context.Context, lastEventID string, send func(endpoint.Event) error) error {
+		send(endpoint.Event{ID: "2", Name: "resumed", Data: lastEventID})
+		return send(endpoint.Event{ID: "3", Data: map[string]string{"street": "Main"}})
+	})
+
+	rec := serveStream(endpoint.WithHeader(resp, "X-Stream", "true"), EncodeSSEResponse(StreamRetry(3*time.Second)), map[string]string{"Last-Event-ID": "1"})
+
+	assert.Equal(t, 200, rec.Code)
+	assert.Equal(t, "text/event-stream", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "true", rec.Header().Get("X-Stream"))
+	assert.True(t, rec.Flushed)
+	assert.Equal(t, "retry: 3000\n\nid: 2\nevent: resumed\ndata: 1\n\nid: 3\ndata: {\"street\":\"Main\"}\n\n", rec.Body.String())
+}
+
+func TestEncodeSSEResponseHeartbeatAndCancellation(t *testing.T) {
+	events := make(chan endpoint.Event)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	go func() {
+		events <- endpoint.Event{Data: "line 1\nline 2"}
+		time.Sleep(30 * time.Millisecond)
+		cancel()
+	}()
+
+	rec := httptest.NewRecorder()
+	err := EncodeSSEResponse(StreamHeartbeat(10*time.Millisecond))(ctx, rec, endpoint.StreamChannel(200, events))
+
+	assert.Nil(t, err)
+	assert.Contains(t, rec.Body.String(), "data: line 1\ndata: line 2\n\n")
+	assert.Contains(t, rec.Body.String(), ": heartbeat\n\n")
+}
+
+func TestEncodeStreamResponseAsNDJSON(t *testing.T) {
+	resp := endpoint.Stream(200, func(ctx context.Context, lastEventID string, send func(endpoint.Event) error) error {
+		send(endpoint.Event{Data: map[string]int{"id": 1}})
+		send(endpoint.Event{Data: map[string]int{"id": 2}})
+		return errors.New("query failed")
+	})
+
+	rec := serveStream(resp, EncodeStreamResponse(EncodeJSONResponse), nil)
+
+	assert.Equal(t, "application/x-ndjson", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "{\"id\":1}\n{\"id\":2}\n{\"error\":\"Internal Server Error\"}\n", rec.Body.String())
+
+	rec = serveStream(endpoint.Response(200, map[string]int{"id": 1}), EncodeStreamResponse(EncodeJSONResponse), nil)
+	assert.JSONEq(t, `{"id":1}`, rec.Body.String())
+}
+
+func TestEncodeSSEResponseHidesInternalErrorsAndSplitsCR(t *testing.T) {
+	resp := endpoint.Stream(200, func(ctx context.Context, lastEventID string, send func(endpoint.Event) error) error {
+		send(endpoint.Event{Data: "a\rid: 9\r\nb"})
+		return errors.New("pq: connection refused")
+	})
+
+	rec := serveStream(resp, EncodeSSEResponse(), nil)
+
+	assert.Equal(t, "data: a\ndata: id: 9\ndata: b\n\nevent: error\ndata: {\"message\":\"Internal Server Error\"}\n\n", rec.Body.String())
+}
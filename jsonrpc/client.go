@@ -0,0 +1,156 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/helderfarias/go-api-kit/endpoint"
+	"github.com/helderfarias/go-api-kit/httptransport"
+	"github.com/helderfarias/go-api-kit/uri"
+	"gopkg.in/resty.v1"
+)
+
+// EncodeRequestFunc converts the endpoint request into the params
+type EncodeRequestFunc func(ctx context.Context, request interface{}) (interface{}, error)
+
+// DecodeResponseFunc converts the result into an endpoint response
+type DecodeResponseFunc func(ctx context.Context, result json.RawMessage) (endpoint.EndpointResponse, error)
+
+// Client wraps a remote method as an endpoint
+type Client struct {
+	client *resty.Client
+	target string
+	method string
+	enc    EncodeRequestFunc
+	dec    DecodeResponseFunc
+	before []httptransport.ClientRequestFunc
+	ids    uint64
+}
+
+// ClientOption sets an optional parameter for clients
+type ClientOption func(c *Client)
+
+// NewClient constructs a client for the method, by default the request is sent as params
+// and the result is decoded as a generic value
+func NewClient(target *uri.URI, method string, options ...ClientOption) *Client {
+	c := &Client{
+		client: resty.New().SetDisableWarn(true),
+		target: target.String(),
+		method: method,
+		enc:    EncodeParams,
+		dec:    DecodeResult(func() interface{} { return new(interface{}) }),
+	}
+
+	for _, o := range options {
+		o(c)
+	}
+
+	return c
+}
+
+// SetClient sets the resty client used (timeouts, retries, TLS...)
+func SetClient(client *resty.Client) ClientOption {
+	return func(c *Client) {
+		c.client = client
+	}
+}
+
+// ClientRequestEncoder sets the params encoder
+func ClientRequestEncoder(enc EncodeRequestFunc) ClientOption {
+	return func(c *Client) {
+		c.enc = enc
+	}
+}
+
+// ClientResponseDecoder sets the result decoder
+func ClientResponseDecoder(dec DecodeResponseFunc) ClientOption {
+	return func(c *Client) {
+		c.dec = dec
+	}
+}
+
+// ClientBefore functions executed on the outgoing request, e.g. to propagate headers
+func ClientBefore(before ...httptransport.ClientRequestFunc) ClientOption {
+	return func(c *Client) {
+		c.before = append(c.before, before...)
+	}
+}
+
+// EncodeParams sends the request as params
+func EncodeParams(ctx context.Context, request interface{}) (interface{}, error) {
+	return request, nil
+}
+
+// DecodeResult decodes the result into the value returned by newResponse
+func DecodeResult(newResponse func() interface{}) DecodeResponseFunc {
+	return func(ctx context.Context, result json.RawMessage) (endpoint.EndpointResponse, error) {
+		data := newResponse()
+
+		if err := json.Unmarshal(result, data); err != nil {
+			return nil, err
+		}
+
+		return endpoint.Response(http.StatusOK, data), nil
+	}
+}
+
+// Endpoint returns an endpoint that calls the remote method. JSON-RPC errors are returned as
+// responses with the equivalent status and the *Error as data, like the HTTP client does
+// with error statuses, so the same middlewares apply.
+func (c *Client) Endpoint() endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+		if ctx == nil {
+			ctx = context.Background()
+		}
+
+		params, err := c.enc(ctx, request)
+		if err != nil {
+			return nil, err
+		}
+
+		body := Request{
+			JSONRPC: Version,
+			Method:  c.method,
+			ID:      json.RawMessage(strconv.FormatUint(atomic.AddUint64(&c.ids, 1), 10)),
+		}
+
+		if params != nil {
+			if body.Params, err = json.Marshal(params); err != nil {
+				return nil, err
+			}
+		}
+
+		req := c.client.R().
+			SetContext(ctx).
+			SetHeader("Content-Type", "application/json").
+			SetBody(body)
+
+		for _, f := range c.before {
+			ctx = f(ctx, req)
+		}
+
+		resp, err := req.Post(c.target)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode() != http.StatusOK {
+			return nil, fmt.Errorf("jsonrpc %v: unexpected status %v", c.method, resp.StatusCode())
+		}
+
+		result := Response{}
+		if err := json.Unmarshal(resp.Body(), &result); err != nil {
+			return nil, err
+		}
+
+		if result.Error != nil {
+			return endpoint.Response(result.Error.StatusCode(), result.Error), nil
+		}
+
+		return c.dec(ctx, result.Result)
+	}
+}
@@ -0,0 +1,34 @@
+package jsonrpc
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/helderfarias/go-api-kit/uri"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientEndpointCallRemoteMethod(t *testing.T) {
+	server := httptest.NewServer(newTestServer())
+	defer server.Close()
+
+	sum := NewClient(uri.NewBuildURI(server.URL), "sum", ClientResponseDecoder(DecodeResult(func() interface{} { return new(int) })))
+
+	resp, err := sum.Endpoint()(nil, sumRequest{A: 2, B: 3})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 200, resp.Code())
+	assert.Equal(t, 5, *resp.Data().(*int))
+}
+
+func TestClientEndpointRemoteError(t *testing.T) {
+	server := httptest.NewServer(newTestServer())
+	defer server.Close()
+
+	resp, err := NewClient(uri.NewBuildURI(server.URL), "find").Endpoint()(nil, nil)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 404, resp.Code())
+	assert.Equal(t, -32004, resp.Data().(*Error).Code)
+	assert.Equal(t, map[string]interface{}{"message": "address not found"}, resp.Data().(*Error).Data)
+}
@@ -0,0 +1,122 @@
+// Package jsonrpc JSON-RPC 2.0 transport over HTTP for endpoint.Endpoint
+package jsonrpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/helderfarias/go-api-kit/apierror"
+)
+
+// Version of the protocol
+const Version = "2.0"
+
+// Standard error codes
+const (
+	ParseError     = -32700
+	InvalidRequest = -32600
+	MethodNotFound = -32601
+	InvalidParams  = -32602
+	InternalError  = -32603
+	// ServerError generic implementation defined error, -32000 to -32099 are reserved for them
+	ServerError = -32000
+)
+
+// Implementation defined errors of the HTTP statuses
+const (
+	Unauthorized    = -32001
+	Forbidden       = -32003
+	NotFound        = -32004
+	Conflict        = -32009
+	TooManyRequests = -32029
+)
+
+// StatusCodes error codes of the EndpointResponse/error statuses, 4xx statuses
+// not listed are ServerError and 5xx statuses are InternalError
+var StatusCodes = map[int]int{
+	http.StatusBadRequest:          InvalidParams,
+	http.StatusUnprocessableEntity: InvalidParams,
+	http.StatusUnauthorized:        Unauthorized,
+	http.StatusForbidden:           Forbidden,
+	http.StatusNotFound:            NotFound,
+	http.StatusConflict:            Conflict,
+	http.StatusTooManyRequests:     TooManyRequests,
+}
+
+// Request JSON-RPC request, ID is empty for notifications
+type Request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	ID      json.RawMessage `json:"id,omitempty"`
+}
+
+// Response JSON-RPC response, with either Result or Error
+type Response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+	ID      json.RawMessage `json:"id"`
+}
+
+// Error JSON-RPC error object
+type Error struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+// NewError constructs an error
+func NewError(code int, message string, data interface{}) *Error {
+	return &Error{Code: code, Message: message, Data: data}
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("jsonrpc %v: %v", e.Code, e.Message)
+}
+
+// StatusCode HTTP status equivalent to the standard and the predefined server error codes,
+// used by the client and by the httptransport error encoder
+func (e *Error) StatusCode() int {
+	switch e.Code {
+	case ParseError, InvalidRequest, InvalidParams, ServerError:
+		return http.StatusBadRequest
+	case MethodNotFound:
+		return http.StatusNotFound
+	case Unauthorized:
+		return http.StatusUnauthorized
+	case Forbidden:
+		return http.StatusForbidden
+	case NotFound:
+		return http.StatusNotFound
+	case Conflict:
+		return http.StatusConflict
+	case TooManyRequests:
+		return http.StatusTooManyRequests
+	}
+
+	return http.StatusInternalServerError
+}
+
+// codeOf error code of the status
+func codeOf(status int) int {
+	if code, ok := StatusCodes[status]; ok {
+		return code
+	}
+	if status >= http.StatusBadRequest && status < http.StatusInternalServerError {
+		return ServerError
+	}
+	return InternalError
+}
+
+// errorOf converts errors returned by the decoders and endpoints, *Error is kept and
+// other errors are mapped by the status of apierror.Public
+func errorOf(err error) *Error {
+	if e, ok := err.(*Error); ok {
+		return e
+	}
+
+	e := apierror.Public(err)
+	return NewError(codeOf(e.StatusCode()), e.Message, e.Details)
+}
@@ -0,0 +1,252 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/helderfarias/go-api-kit/endpoint"
+	"github.com/helderfarias/go-api-kit/httptransport"
+	"github.com/sirupsen/logrus"
+)
+
+// DecodeRequestFunc extracts the endpoint request from the params
+type DecodeRequestFunc func(ctx context.Context, params json.RawMessage) (interface{}, error)
+
+// EncodeResponseFunc converts the endpoint response into the result
+type EncodeResponseFunc func(ctx context.Context, resp endpoint.EndpointResponse) (interface{}, error)
+
+// EndpointCodec endpoint of a method with its codecs, nil Decode ignores the
+// params and nil Encode uses EndpointResponse.Data as result
+type EndpointCodec struct {
+	Endpoint endpoint.Endpoint
+	Decode   DecodeRequestFunc
+	Encode   EncodeResponseFunc
+}
+
+// EndpointCodecMap methods served, by name
+type EndpointCodecMap map[string]EndpointCodec
+
+// Server serves the methods and implements http.Handler
+type Server struct {
+	ecm       EndpointCodecMap
+	before    []httptransport.RequestFunc
+	readLimit int64
+}
+
+// ServerOption sets an optional parameter for servers
+type ServerOption func(s *Server)
+
+type contextKey string
+
+const methodContextKey contextKey = "jsonrpc.method"
+
+// NewServer constructs a server for the methods
+func NewServer(ecm EndpointCodecMap, options ...ServerOption) *Server {
+	s := &Server{ecm: ecm, readLimit: 1024 * 1024}
+
+	for _, o := range options {
+		o(s)
+	}
+
+	return s
+}
+
+// ServerBefore functions executed on the HTTP request before the calls, e.g. httptransport.PopulateAuthToken
+func ServerBefore(before ...httptransport.RequestFunc) ServerOption {
+	return func(s *Server) {
+		s.before = append(s.before, before...)
+	}
+}
+
+// ServerReadLimit maximum size of the request body, defaults to 1MB
+func ServerReadLimit(limit int64) ServerOption {
+	return func(s *Server) {
+		s.readLimit = limit
+	}
+}
+
+// DecodeParams decodes the params into the value returned by newRequest, errors are InvalidParams
+func DecodeParams(newRequest func() interface{}) DecodeRequestFunc {
+	return func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+		request := newRequest()
+
+		if len(params) == 0 {
+			return request, nil
+		}
+
+		if err := json.Unmarshal(params, request); err != nil {
+			return nil, NewError(InvalidParams, "invalid params", err.Error())
+		}
+
+		return request, nil
+	}
+}
+
+// MethodFromContext the method being called
+func MethodFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+
+	method, _ := ctx.Value(methodContextKey).(string)
+	return method
+}
+
+// ServeHTTP implements http.Handler, batches are answered with an array of the responses
+// of the calls that aren't notifications, 204 when there is none
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		s.write(w, http.StatusMethodNotAllowed, errorResponse(nil, NewError(InvalidRequest, "method not allowed", nil)))
+		return
+	}
+
+	ctx := r.Context()
+	for _, f := range s.before {
+		ctx = f(ctx, r)
+	}
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, s.readLimit))
+	if err != nil && int64(len(body)) >= s.readLimit {
+		s.write(w, http.StatusRequestEntityTooLarge, errorResponse(nil, NewError(InvalidRequest, "request too large", nil)))
+		return
+	}
+	if err != nil {
+		s.write(w, http.StatusOK, errorResponse(nil, NewError(ParseError, "parse error", err.Error())))
+		return
+	}
+
+	body = bytes.TrimSpace(body)
+
+	if len(body) > 0 && body[0] == '[' {
+		batch := []json.RawMessage{}
+		if err := json.Unmarshal(body, &batch); err != nil {
+			s.write(w, http.StatusOK, errorResponse(nil, NewError(ParseError, "parse error", err.Error())))
+			return
+		}
+
+		if len(batch) == 0 {
+			s.write(w, http.StatusOK, errorResponse(nil, NewError(InvalidRequest, "empty batch", nil)))
+			return
+		}
+
+		responses := []*Response{}
+		for _, raw := range batch {
+			if resp := s.call(ctx, raw); resp != nil {
+				responses = append(responses, resp)
+			}
+		}
+
+		if len(responses) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		s.write(w, http.StatusOK, responses)
+		return
+	}
+
+	resp := s.call(ctx, body)
+	if resp == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	s.write(w, http.StatusOK, resp)
+}
+
+// call runs a single request, nil for notifications
+func (s *Server) call(ctx context.Context, raw json.RawMessage) *Response {
+	req := Request{}
+	if err := json.Unmarshal(raw, &req); err != nil {
+		if _, ok := err.(*json.SyntaxError); ok {
+			return errorResponse(nil, NewError(ParseError, "parse error", err.Error()))
+		}
+		return errorResponse(nil, NewError(InvalidRequest, "invalid request", err.Error()))
+	}
+
+	if req.JSONRPC != Version || req.Method == "" {
+		return errorResponse(req.ID, NewError(InvalidRequest, "invalid request", nil))
+	}
+
+	notification := len(req.ID) == 0
+
+	result, rpcErr := s.invoke(context.WithValue(ctx, methodContextKey, req.Method), req)
+	if notification {
+		if rpcErr != nil {
+			logrus.WithField("jsonrpc.notification", req.Method).Debug(rpcErr)
+		}
+		return nil
+	}
+
+	if rpcErr != nil {
+		return errorResponse(req.ID, rpcErr)
+	}
+
+	return &Response{JSONRPC: Version, Result: result, ID: req.ID}
+}
+
+func (s *Server) invoke(ctx context.Context, req Request) (json.RawMessage, *Error) {
+	ec, ok := s.ecm[req.Method]
+	if !ok {
+		return nil, NewError(MethodNotFound, "method not found", req.Method)
+	}
+
+	var request interface{}
+	if ec.Decode != nil {
+		r, err := ec.Decode(ctx, req.Params)
+		if err != nil {
+			return nil, errorOf(err)
+		}
+		request = r
+	}
+
+	resp, err := ec.Endpoint(ctx, request)
+	if err != nil {
+		logrus.WithField("jsonrpc.method", req.Method).Debug(err)
+		return nil, errorOf(err)
+	}
+
+	if resp != nil && resp.Code() >= http.StatusInternalServerError {
+		logrus.WithField("jsonrpc.method", req.Method).Error(resp.Data())
+		return nil, NewError(codeOf(resp.Code()), http.StatusText(resp.Code()), nil)
+	}
+
+	if resp != nil && resp.Code() >= http.StatusBadRequest {
+		return nil, NewError(codeOf(resp.Code()), http.StatusText(resp.Code()), resp.Data())
+	}
+
+	var result interface{}
+	if ec.Encode != nil {
+		r, err := ec.Encode(ctx, resp)
+		if err != nil {
+			return nil, errorOf(err)
+		}
+		result = r
+	} else if resp != nil {
+		result = resp.Data()
+	}
+
+	enc, err := json.Marshal(result)
+	if err != nil {
+		return nil, errorOf(err)
+	}
+
+	return enc, nil
+}
+
+func (s *Server) write(w http.ResponseWriter, code int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(body)
+}
+
+func errorResponse(id json.RawMessage, err *Error) *Response {
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+	return &Response{JSONRPC: Version, Error: err, ID: id}
+}
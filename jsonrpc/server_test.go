@@ -0,0 +1,122 @@
+package jsonrpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/helderfarias/go-api-kit/apierror"
+	"github.com/helderfarias/go-api-kit/endpoint"
+	"github.com/stretchr/testify/assert"
+)
+
+type sumRequest struct {
+	A int `json:"a"`
+	B int `json:"b"`
+}
+
+func newTestServer() *Server {
+	return NewServer(EndpointCodecMap{
+		"sum": {
+			Endpoint: func(ctx context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+				r := request.(*sumRequest)
+				return endpoint.Response(200, r.A+r.B), nil
+			},
+			Decode: DecodeParams(func() interface{} { return &sumRequest{} }),
+		},
+		"find": {
+			Endpoint: func(ctx context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+				return endpoint.Response(404, map[string]string{"message": "address not found"}), nil
+			},
+		},
+		"validate": {
+			Endpoint: func(ctx context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+				return nil, apierror.Validation("invalid address", []string{"street"})
+			},
+		},
+		"unavailable": {
+			Endpoint: func(ctx context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+				return endpoint.Response(503, map[string]string{"message": "redis: dial tcp 10.0.0.5:6379"}), nil
+			},
+		},
+		"fail": {
+			Endpoint: func(ctx context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+				return nil, errors.New("database down")
+			},
+		},
+	})
+}
+
+func post(s *Server, body string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest("POST", "/rpc", strings.NewReader(body)))
+	return rec
+}
+
+func TestServerCall(t *testing.T) {
+	rec := post(newTestServer(), `{"jsonrpc":"2.0","method":"sum","params":{"a":1,"b":2},"id":"a1"}`)
+
+	assert.Equal(t, 200, rec.Code)
+	assert.JSONEq(t, `{"jsonrpc":"2.0","result":3,"id":"a1"}`, rec.Body.String())
+}
+
+func TestServerErrors(t *testing.T) {
+	s := newTestServer()
+
+	assert.JSONEq(t, `{"jsonrpc":"2.0","error":{"code":-32700,"message":"parse error","data":"unexpected end of JSON input"},"id":null}`, post(s, `{"jsonrpc"`).Body.String())
+	assert.JSONEq(t, `{"jsonrpc":"2.0","error":{"code":-32600,"message":"invalid request"},"id":1}`, post(s, `{"jsonrpc":"1.0","method":"sum","id":1}`).Body.String())
+	assert.JSONEq(t, `{"jsonrpc":"2.0","error":{"code":-32601,"message":"method not found","data":"nop"},"id":1}`, post(s, `{"jsonrpc":"2.0","method":"nop","id":1}`).Body.String())
+	assert.Contains(t, post(s, `{"jsonrpc":"2.0","method":"sum","params":[1],"id":1}`).Body.String(), `"code":-32602`)
+	assert.JSONEq(t, `{"jsonrpc":"2.0","error":{"code":-32004,"message":"Not Found","data":{"message":"address not found"}},"id":1}`, post(s, `{"jsonrpc":"2.0","method":"find","id":1}`).Body.String())
+	assert.JSONEq(t, `{"jsonrpc":"2.0","error":{"code":-32602,"message":"invalid address","data":["street"]},"id":1}`, post(s, `{"jsonrpc":"2.0","method":"validate","id":1}`).Body.String())
+	assert.JSONEq(t, `{"jsonrpc":"2.0","error":{"code":-32603,"message":"Internal Server Error"},"id":1}`, post(s, `{"jsonrpc":"2.0","method":"fail","id":1}`).Body.String())
+}
+
+func TestServerBatchAndNotifications(t *testing.T) {
+	s := newTestServer()
+
+	rec := post(s, `[
+		{"jsonrpc":"2.0","method":"sum","params":{"a":1,"b":2},"id":1},
+		{"jsonrpc":"2.0","method":"sum","params":{"a":5,"b":5}},
+		{"jsonrpc":"2.0","method":"nop","id":2},
+		1
+	]`)
+
+	assert.JSONEq(t, `[
+		{"jsonrpc":"2.0","result":3,"id":1},
+		{"jsonrpc":"2.0","error":{"code":-32601,"message":"method not found","data":"nop"},"id":2},
+		{"jsonrpc":"2.0","error":{"code":-32600,"message":"invalid request","data":"json: cannot unmarshal number into Go value of type jsonrpc.Request"},"id":null}
+	]`, rec.Body.String())
+
+	assert.Equal(t, 204, post(s, `[{"jsonrpc":"2.0","method":"sum","params":{"a":1,"b":2}}]`).Code)
+	assert.Equal(t, 204, post(s, `{"jsonrpc":"2.0","method":"fail"}`).Code)
+	assert.Contains(t, post(s, `[]`).Body.String(), `"code":-32600`)
+}
+
+func TestServerHidesTheDataOfServerErrorResponses(t *testing.T) {
+	rec := post(newTestServer(), `{"jsonrpc":"2.0","method":"unavailable","id":1}`)
+
+	assert.JSONEq(t, `{"jsonrpc":"2.0","error":{"code":-32603,"message":"Service Unavailable"},"id":1}`, rec.Body.String())
+}
+
+func TestServerReadLimit(t *testing.T) {
+	s := NewServer(EndpointCodecMap{}, ServerReadLimit(16))
+
+	rec := post(s, `{"jsonrpc":"2.0","method":"sum","id":1}`)
+
+	assert.Equal(t, 413, rec.Code)
+	assert.JSONEq(t, `{"jsonrpc":"2.0","error":{"code":-32600,"message":"request too large"},"id":null}`, rec.Body.String())
+}
+
+func TestErrorStatusCodeIgnoresCustomCodes(t *testing.T) {
+	StatusCodes[http.StatusGone] = NotFound
+	defer delete(StatusCodes, http.StatusGone)
+
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, http.StatusNotFound, NewError(NotFound, "not found", nil).StatusCode())
+	}
+	assert.Equal(t, http.StatusInternalServerError, NewError(InternalError, "internal error", nil).StatusCode())
+}
@@ -17,6 +17,17 @@ type Publisher interface {
 	Publish(stream, subj string, sender interface{}, o ...PubOpts) (interface{}, error)
 }
 
+// Server message broker connection handing out publishers and subscribers
+type Server interface {
+	Pub() Publisher
+
+	Sub() Subscriber
+
+	IsConnected() bool
+
+	Close()
+}
+
 type options struct {
 	action endpoint.Endpoint
 	args   map[string]interface{}
@@ -26,6 +37,18 @@ type SubRec func(o *options)
 
 type PubOpts func(o *options)
 
+// Header sets a header of the published message, e.g. the trace context
+func Header(key, value string) PubOpts {
+	return func(o *options) {
+		headers, _ := o.args["headers"].(map[string]string)
+		if headers == nil {
+			headers = map[string]string{}
+			o.args["headers"] = headers
+		}
+		headers[key] = value
+	}
+}
+
 type emptySub struct {
 }
 
@@ -69,6 +69,16 @@ func (p *natsServer) Close() {
 	p.nc.Close()
 }
 
+// Conn the underlying connection, nil when the connect failed
+func (p *natsServer) Conn() *nats.Conn {
+	return p.nc
+}
+
+// IsConnected reports whether the connection is up, used by the health checks
+func (p *natsServer) IsConnected() bool {
+	return p.nc != nil && p.nc.IsConnected()
+}
+
 func (p *natsServer) Pub() Publisher {
 	if p.nc == nil {
 		return &emptyPub{}
@@ -118,6 +128,8 @@ func (p *natsPublisher) Publish(stream, subj string, sender interface{}, opts ..
 	}
 
 	args := []nats.PubOpt{}
+	msg := nats.NewMsg(subj)
+	msg.Data = data
 
 	for _, fill := range opts {
 		o := options{args: map[string]interface{}{}}
@@ -126,9 +138,15 @@ func (p *natsPublisher) Publish(stream, subj string, sender interface{}, opts ..
 		if values, ok := o.args["pub_args"].([]nats.PubOpt); ok {
 			args = append(args, values...)
 		}
+
+		if headers, ok := o.args["headers"].(map[string]string); ok {
+			for k, v := range headers {
+				msg.Header.Set(k, v)
+			}
+		}
 	}
 
-	pub, err := js.Publish(subj, data, args...)
+	pub, err := js.PublishMsg(msg, args...)
 	if err != nil {
 		logrus.Error(err)
 		return nil, err
@@ -0,0 +1,124 @@
+package metrics
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TextContentType Prometheus text exposition format
+const TextContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+var (
+	labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+// Handler serves the metrics in the text format (GET /metrics)
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", TextContentType)
+		if err := r.WriteText(w); err != nil {
+			logrus.Error(err)
+		}
+	})
+}
+
+// WriteText writes the families sorted by name in the Prometheus text format
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.RLock()
+	hooks := append([]func(){}, r.onScrape...)
+	r.mu.RUnlock()
+
+	for _, hook := range hooks {
+		hook()
+	}
+
+	r.mu.RLock()
+	families := make([]*family, 0, len(r.families))
+	for _, f := range r.families {
+		families = append(families, f)
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(families, func(i, j int) bool { return families[i].name < families[j].name })
+
+	buf := bufio.NewWriter(w)
+	for _, f := range families {
+		f.write(buf)
+	}
+
+	return buf.Flush()
+}
+
+func (f *family) write(w *bufio.Writer) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(f.series) == 0 {
+		return
+	}
+
+	if f.help != "" {
+		w.WriteString("# HELP " + f.name + " " + helpEscaper.Replace(f.help) + "\n")
+	}
+	w.WriteString("# TYPE " + f.name + " " + f.kind + "\n")
+
+	keys := make([]string, 0, len(f.series))
+	for key := range f.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		s := f.series[key]
+
+		if f.kind != kindHistogram {
+			writeSample(w, f.name, f.labels, s.labelValues, "", "", s.value)
+			continue
+		}
+
+		for i, upper := range f.buckets {
+			writeSample(w, f.name+"_bucket", f.labels, s.labelValues, "le", formatFloat(upper), float64(s.counts[i]))
+		}
+		writeSample(w, f.name+"_bucket", f.labels, s.labelValues, "le", "+Inf", float64(s.count))
+		writeSample(w, f.name+"_sum", f.labels, s.labelValues, "", "", s.sum)
+		writeSample(w, f.name+"_count", f.labels, s.labelValues, "", "", float64(s.count))
+	}
+}
+
+func writeSample(w *bufio.Writer, name string, labels, values []string, extraLabel, extraValue string, v float64) {
+	w.WriteString(name)
+
+	pairs := []string{}
+	for i, label := range labels {
+		pairs = append(pairs, label+`="`+labelEscaper.Replace(values[i])+`"`)
+	}
+	if extraLabel != "" {
+		pairs = append(pairs, extraLabel+`="`+extraValue+`"`)
+	}
+
+	if len(pairs) > 0 {
+		w.WriteString("{" + strings.Join(pairs, ",") + "}")
+	}
+
+	w.WriteString(" " + formatFloat(v) + "\n")
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,170 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/helderfarias/go-api-kit/cache"
+	"github.com/helderfarias/go-api-kit/cron"
+	"github.com/helderfarias/go-api-kit/db"
+	"github.com/helderfarias/go-api-kit/endpoint"
+	"github.com/helderfarias/go-api-kit/mb"
+	"gopkg.in/redis.v5"
+)
+
+type instrumentedCache struct {
+	cache.CacheServer
+	name     string
+	requests *Counter
+}
+
+// instrumentedRedisCache keeps the Pinger and ScriptRunner of the Redis cache visible
+type instrumentedRedisCache struct {
+	*instrumentedCache
+	redis redisCache
+}
+
+type redisCache interface {
+	cache.Pinger
+	cache.ScriptRunner
+}
+
+type instrumentedPublisher struct {
+	mb.Publisher
+	published *Counter
+}
+
+// DBStats samples the pool of the factory on each scrape, labeled by name
+func (r *Registry) DBStats(name string, factory db.ConnectionFactory) {
+	stater, ok := factory.Delegate().(interface{ Stats() sql.DBStats })
+	if !ok {
+		return
+	}
+
+	maxOpen := r.Gauge("db_max_open_connections", "Maximum number of open connections to the database.", "db")
+	open := r.Gauge("db_open_connections", "Established connections, in use and idle.", "db")
+	inUse := r.Gauge("db_in_use_connections", "Connections currently in use.", "db")
+	idle := r.Gauge("db_idle_connections", "Idle connections.", "db")
+	waitCount := r.Counter("db_wait_count_total", "Connections waited for.", "db")
+	waitDuration := r.Counter("db_wait_duration_seconds_total", "Time blocked waiting for a new connection.", "db")
+	maxIdleClosed := r.Counter("db_max_idle_closed_total", "Connections closed due to the idle limit.", "db")
+	maxLifetimeClosed := r.Counter("db_max_lifetime_closed_total", "Connections closed due to the max lifetime.", "db")
+
+	r.OnScrape(func() {
+		stats := stater.Stats()
+
+		maxOpen.Set(float64(stats.MaxOpenConnections), name)
+		open.Set(float64(stats.OpenConnections), name)
+		inUse.Set(float64(stats.InUse), name)
+		idle.Set(float64(stats.Idle), name)
+		waitCount.set(float64(stats.WaitCount), name)
+		waitDuration.set(stats.WaitDuration.Seconds(), name)
+		maxIdleClosed.set(float64(stats.MaxIdleClosed), name)
+		maxLifetimeClosed.set(float64(stats.MaxLifetimeClosed), name)
+	})
+}
+
+// InstrumentCache counts the Get hits and misses of the server, labeled by name
+func (r *Registry) InstrumentCache(name string, server cache.CacheServer) cache.CacheServer {
+	c := &instrumentedCache{
+		CacheServer: server,
+		name:        name,
+		requests:    r.Counter("cache_requests_total", "Cache lookups by result (hit, miss or error).", "cache", "result"),
+	}
+
+	if rc, ok := server.(redisCache); ok {
+		return &instrumentedRedisCache{instrumentedCache: c, redis: rc}
+	}
+
+	return c
+}
+
+// InstrumentPublisher counts the published messages by subject and result
+func (r *Registry) InstrumentPublisher(pub mb.Publisher) mb.Publisher {
+	return &instrumentedPublisher{
+		Publisher: pub,
+		published: r.Counter("nats_published_total", "Messages published by subject and result.", "subject", "result"),
+	}
+}
+
+// InstrumentConsumer counts and times the messages handled by the subscriber endpoint, e.g.
+// sub.Subscribe(stream, subject, consumer, metrics.Default.InstrumentConsumer(subject, e))
+func (r *Registry) InstrumentConsumer(subject string, next endpoint.Endpoint) endpoint.Endpoint {
+	consumed := r.Counter("nats_consumed_total", "Messages consumed by subject and result.", "subject", "result")
+	duration := r.Histogram("nats_consume_duration_seconds", "Time handling a consumed message.", nil, "subject")
+
+	return func(ctx context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+		start := time.Now()
+
+		resp, err := next(ctx, request)
+
+		duration.Observe(time.Since(start).Seconds(), subject)
+		consumed.Inc(subject, result(resp, err))
+
+		return resp, err
+	}
+}
+
+// InstrumentJob times the runs of the cron task, panics are counted and propagated
+func (r *Registry) InstrumentJob(name string, task cron.Scheduled) cron.Scheduled {
+	runs := r.Counter("cron_job_runs_total", "Cron job runs by result (success or panic).", "job", "result")
+	duration := r.Histogram("cron_job_duration_seconds", "Cron job run duration.", []float64{.1, .5, 1, 5, 10, 30, 60, 300, 900, 3600}, "job")
+
+	return func() {
+		start := time.Now()
+		outcome := "panic"
+
+		defer func() {
+			duration.Observe(time.Since(start).Seconds(), name)
+			runs.Inc(name, outcome)
+		}()
+
+		task()
+		outcome = "success"
+	}
+}
+
+func (c *instrumentedCache) Get(key string, target interface{}) (interface{}, error) {
+	value, err := c.CacheServer.Get(key, target)
+
+	switch {
+	case err == redis.Nil:
+		c.requests.Inc(c.name, "miss")
+	case err != nil:
+		c.requests.Inc(c.name, "error")
+	case value == nil || value == "":
+		c.requests.Inc(c.name, "miss")
+	default:
+		c.requests.Inc(c.name, "hit")
+	}
+
+	return value, err
+}
+
+func (c *instrumentedRedisCache) Ping() error {
+	return c.redis.Ping()
+}
+
+func (c *instrumentedRedisCache) Eval(script string, keys []string, args ...interface{}) (interface{}, error) {
+	return c.redis.Eval(script, keys, args...)
+}
+
+func (p *instrumentedPublisher) Publish(stream, subj string, sender interface{}, opts ...mb.PubOpts) (interface{}, error) {
+	ack, err := p.Publisher.Publish(stream, subj, sender, opts...)
+
+	if err != nil {
+		p.published.Inc(subj, "error")
+	} else {
+		p.published.Inc(subj, "success")
+	}
+
+	return ack, err
+}
+
+func result(resp endpoint.EndpointResponse, err error) string {
+	if err != nil || (resp != nil && resp.Code() >= 400) {
+		return "error"
+	}
+	return "success"
+}
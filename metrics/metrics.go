@@ -0,0 +1,207 @@
+// Package metrics counters, gauges and histograms with labels exposed in the Prometheus
+// text format, e.g.
+//
+//	requests := metrics.Default.Counter("orders_created_total", "Orders created.", "channel")
+//	requests.Inc("web")
+//
+//	r.Handle("GET", "/metrics", metrics.Default.Handler())
+package metrics
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	kindCounter   = "counter"
+	kindGauge     = "gauge"
+	kindHistogram = "histogram"
+)
+
+// DefaultBuckets latency buckets in seconds
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Default registry used by the kit instrumentation
+var Default = NewRegistry()
+
+// Registry metric families by name
+type Registry struct {
+	mu       sync.RWMutex
+	families map[string]*family
+	onScrape []func()
+}
+
+// Counter monotonic value by label values
+type Counter struct {
+	family *family
+}
+
+// Gauge value that goes up and down by label values
+type Gauge struct {
+	family *family
+}
+
+// Histogram observations counted in cumulative buckets by label values
+type Histogram struct {
+	family *family
+}
+
+type family struct {
+	name    string
+	help    string
+	kind    string
+	labels  []string
+	buckets []float64
+
+	mu     sync.Mutex
+	series map[string]*series
+}
+
+type series struct {
+	labelValues []string
+	value       float64
+	counts      []uint64
+	sum         float64
+	count       uint64
+}
+
+// NewRegistry constructs an empty registry
+func NewRegistry() *Registry {
+	return &Registry{families: map[string]*family{}}
+}
+
+// Counter registers the counter, registering the same name again returns the existing one
+func (r *Registry) Counter(name, help string, labels ...string) *Counter {
+	return &Counter{family: r.register(name, help, kindCounter, labels, nil)}
+}
+
+// Gauge registers the gauge, registering the same name again returns the existing one
+func (r *Registry) Gauge(name, help string, labels ...string) *Gauge {
+	return &Gauge{family: r.register(name, help, kindGauge, labels, nil)}
+}
+
+// Histogram registers the histogram, nil buckets uses DefaultBuckets
+func (r *Registry) Histogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+
+	sorted := append([]float64{}, buckets...)
+	sort.Float64s(sorted)
+
+	return &Histogram{family: r.register(name, help, kindHistogram, labels, sorted)}
+}
+
+// OnScrape runs fn before each exposition, used to sample values like pool stats
+func (r *Registry) OnScrape(fn func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onScrape = append(r.onScrape, fn)
+}
+
+// Inc adds 1, label values are given in the order of the labels, missing ones are empty
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add adds v, negative values are ignored
+func (c *Counter) Add(v float64, labelValues ...string) {
+	if v < 0 {
+		return
+	}
+
+	c.family.update(labelValues, func(s *series) { s.value += v })
+}
+
+// set replaces the value, used for totals sampled from elsewhere (e.g. sql.DBStats)
+func (c *Counter) set(v float64, labelValues ...string) {
+	c.family.update(labelValues, func(s *series) { s.value = v })
+}
+
+// Set replaces the value
+func (g *Gauge) Set(v float64, labelValues ...string) {
+	g.family.update(labelValues, func(s *series) { s.value = v })
+}
+
+// Add adds v, which may be negative
+func (g *Gauge) Add(v float64, labelValues ...string) {
+	g.family.update(labelValues, func(s *series) { s.value += v })
+}
+
+// Inc adds 1
+func (g *Gauge) Inc(labelValues ...string) {
+	g.Add(1, labelValues...)
+}
+
+// Dec subtracts 1
+func (g *Gauge) Dec(labelValues ...string) {
+	g.Add(-1, labelValues...)
+}
+
+// Observe records v, e.g. a duration in seconds
+func (h *Histogram) Observe(v float64, labelValues ...string) {
+	buckets := h.family.buckets
+
+	h.family.update(labelValues, func(s *series) {
+		if s.counts == nil {
+			s.counts = make([]uint64, len(buckets))
+		}
+
+		for i, upper := range buckets {
+			if v <= upper {
+				s.counts[i]++
+			}
+		}
+
+		s.sum += v
+		s.count++
+	})
+}
+
+func (r *Registry) register(name, help, kind string, labels []string, buckets []float64) *family {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if f, ok := r.families[name]; ok {
+		if f.kind != kind || strings.Join(f.labels, ",") != strings.Join(labels, ",") {
+			panic(fmt.Sprintf("metrics: %v already registered as %v", name, f))
+		}
+		return f
+	}
+
+	f := &family{
+		name:    name,
+		help:    help,
+		kind:    kind,
+		labels:  labels,
+		buckets: buckets,
+		series:  map[string]*series{},
+	}
+	r.families[name] = f
+
+	return f
+}
+
+func (f *family) update(labelValues []string, fn func(s *series)) {
+	values := make([]string, len(f.labels))
+	copy(values, labelValues)
+
+	key := strings.Join(values, "\xff")
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	s, ok := f.series[key]
+	if !ok {
+		s = &series{labelValues: values}
+		f.series[key] = s
+	}
+
+	fn(s)
+}
+
+func (f *family) String() string {
+	return fmt.Sprintf("%v %v%v", f.kind, f.name, f.labels)
+}
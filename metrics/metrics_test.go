@@ -0,0 +1,145 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/helderfarias/go-api-kit/cache"
+	"github.com/helderfarias/go-api-kit/endpoint"
+	"github.com/helderfarias/go-api-kit/mb"
+	wrapper "github.com/helderfarias/sqlx-wrapper/db"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeDB struct{}
+
+type fakeFactory struct{}
+
+type fakePublisher struct {
+	err error
+}
+
+func (fakeDB) Stats() sql.DBStats {
+	return sql.DBStats{MaxOpenConnections: 10, OpenConnections: 3, InUse: 1, Idle: 2, WaitCount: 7, WaitDuration: 1500 * time.Millisecond}
+}
+
+func (fakeFactory) NewConnection() wrapper.UnitOfWork                         { return nil }
+func (fakeFactory) NewConnectionWithTransaction() (wrapper.UnitOfWork, error) { return nil, nil }
+func (fakeFactory) Delegate() interface{}                                     { return fakeDB{} }
+func (fakeFactory) Close() error                                              { return nil }
+
+func (p fakePublisher) Publish(stream, subj string, sender interface{}, o ...mb.PubOpts) (interface{}, error) {
+	return nil, p.err
+}
+
+func text(r *Registry) string {
+	buf := &bytes.Buffer{}
+	r.WriteText(buf)
+	return buf.String()
+}
+
+func TestWriteTextCountersAndGauges(t *testing.T) {
+	r := NewRegistry()
+	r.Counter("orders_total", "Orders\ncreated.", "channel").Add(2, "web")
+	r.Counter("orders_total", "Orders created.", "channel").Inc(`mo"bile`)
+	r.Gauge("queue_size", "").Set(4)
+	r.Gauge("unused", "Never set.")
+
+	assert.Equal(t, `# HELP orders_total Orders\ncreated.
+# TYPE orders_total counter
+orders_total{channel="mo\"bile"} 1
+orders_total{channel="web"} 2
+# TYPE queue_size gauge
+queue_size 4
+`, text(r))
+}
+
+func TestWriteTextHistogram(t *testing.T) {
+	r := NewRegistry()
+	h := r.Histogram("latency_seconds", "Latency.", []float64{1, 0.1}, "code")
+	h.Observe(0.05, "200")
+	h.Observe(0.5, "200")
+	h.Observe(3, "200")
+
+	assert.Equal(t, `# HELP latency_seconds Latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{code="200",le="0.1"} 1
+latency_seconds_bucket{code="200",le="1"} 2
+latency_seconds_bucket{code="200",le="+Inf"} 3
+latency_seconds_sum{code="200"} 3.55
+latency_seconds_count{code="200"} 3
+`, text(r))
+}
+
+func TestRegisterConflictPanics(t *testing.T) {
+	r := NewRegistry()
+	r.Counter("jobs_total", "", "job")
+
+	assert.Panics(t, func() { r.Gauge("jobs_total", "", "job") })
+	assert.Panics(t, func() { r.Counter("jobs_total", "", "queue") })
+}
+
+func TestHandler(t *testing.T) {
+	r := NewRegistry()
+	r.DBStats("main", fakeFactory{})
+
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	assert.Equal(t, TextContentType, rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Body.String(), `db_open_connections{db="main"} 3`)
+	assert.Contains(t, rec.Body.String(), `db_wait_count_total{db="main"} 7`)
+	assert.Contains(t, rec.Body.String(), `db_wait_duration_seconds_total{db="main"} 1.5`)
+}
+
+func TestInstrumentCache(t *testing.T) {
+	r := NewRegistry()
+	c := r.InstrumentCache("local", cache.NewCacheServer())
+
+	_, isPinger := c.(cache.Pinger)
+	assert.False(t, isPinger)
+
+	c.Set("a", "value", time.Minute)
+	target := ""
+	c.Get("a", &target)
+	c.Get("b", &target)
+
+	assert.Contains(t, text(r), `cache_requests_total{cache="local",result="hit"} 1`)
+	assert.Contains(t, text(r), `cache_requests_total{cache="local",result="miss"} 1`)
+}
+
+func TestInstrumentNATS(t *testing.T) {
+	r := NewRegistry()
+
+	r.InstrumentPublisher(fakePublisher{}).Publish("orders", "orders.created", "{}")
+	r.InstrumentPublisher(fakePublisher{err: errors.New("no stream")}).Publish("orders", "orders.created", "{}")
+
+	consumer := r.InstrumentConsumer("orders.created", func(ctx context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+		return endpoint.Response(500, nil), nil
+	})
+	consumer(context.Background(), nil)
+
+	out := text(r)
+	assert.Contains(t, out, `nats_published_total{subject="orders.created",result="error"} 1`)
+	assert.Contains(t, out, `nats_published_total{subject="orders.created",result="success"} 1`)
+	assert.Contains(t, out, `nats_consumed_total{subject="orders.created",result="error"} 1`)
+	assert.Contains(t, out, `nats_consume_duration_seconds_count{subject="orders.created"} 1`)
+}
+
+func TestInstrumentJob(t *testing.T) {
+	r := NewRegistry()
+
+	r.InstrumentJob("cleanup", func() {})()
+	failing := r.InstrumentJob("cleanup", func() { panic("boom") })
+	assert.Panics(t, func() { failing() })
+
+	out := text(r)
+	assert.Contains(t, out, `cron_job_runs_total{job="cleanup",result="panic"} 1`)
+	assert.Contains(t, out, `cron_job_runs_total{job="cleanup",result="success"} 1`)
+	assert.Contains(t, out, `cron_job_duration_seconds_count{job="cleanup"} 2`)
+}
@@ -0,0 +1,118 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/helderfarias/go-api-kit/auth"
+	"github.com/helderfarias/go-api-kit/endpoint"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// Policy decides whether the claims can perform the request, resource
+// level checks can inspect the request object
+type Policy func(ctx context.Context, claims *auth.Claims, request interface{}) bool
+
+// AuthorizeOptions authorization configurations
+type AuthorizeOptions struct {
+	// Roles the claims must have any of them
+	Roles []string
+	// Scopes the claims must have all of them
+	Scopes     []string
+	Policy     Policy
+	OnListener func(event string, name string)
+}
+
+// Authorize checks the claims stored by the JWT middleware against the required roles,
+// scopes and policy, responding 403 when denied. Roles and scopes can be overridden by
+// ops through viper keys "authorization_<name>_roles" and "authorization_<name>_scopes",
+// read on every request so config reloads take effect.
+func Authorize(name string, options ...AuthorizeOptions) endpoint.Middleware {
+	opt := AuthorizeOptions{OnListener: DefaultListener}
+	if len(options) >= 1 {
+		opt = options[0]
+		if opt.OnListener == nil {
+			opt.OnListener = DefaultListener
+		}
+	}
+
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(parent context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+			claims, ok := auth.ClaimsFromContext(parent)
+			if !ok || claims == nil {
+				return endpoint.Response(http.StatusUnauthorized, map[string]string{"message": "authentication required"}), nil
+			}
+
+			roles := opt.Roles
+			if key := fmt.Sprintf("authorization_%v_roles", name); viper.IsSet(key) {
+				roles = viper.GetStringSlice(key)
+			}
+
+			scopes := opt.Scopes
+			if key := fmt.Sprintf("authorization_%v_scopes", name); viper.IsSet(key) {
+				scopes = viper.GetStringSlice(key)
+			}
+
+			if !hasAnyRole(claims, roles) || !hasAllScopes(claims, scopes) ||
+				(opt.Policy != nil && !opt.Policy(parent, claims, request)) {
+				logrus.WithField("authorization.denied", claims.Subject).Debug("Authorize")
+				opt.OnListener("denied", name)
+				return endpoint.Response(http.StatusForbidden, map[string]string{"message": "access denied"}), nil
+			}
+
+			return next(parent, request)
+		}
+	}
+}
+
+// OwnerPolicy allows the request when the claims subject owns the resource
+func OwnerPolicy(owner func(request interface{}) string) Policy {
+	return func(ctx context.Context, claims *auth.Claims, request interface{}) bool {
+		return claims.Subject != "" && claims.Subject == owner(request)
+	}
+}
+
+// AnyPolicy allows the request when one of the policies allows it
+func AnyPolicy(policies ...Policy) Policy {
+	return func(ctx context.Context, claims *auth.Claims, request interface{}) bool {
+		for _, p := range policies {
+			if p(ctx, claims, request) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// RolePolicy allows the request when the claims have the role
+func RolePolicy(role string) Policy {
+	return func(ctx context.Context, claims *auth.Claims, request interface{}) bool {
+		return claims.HasRole(role)
+	}
+}
+
+func hasAnyRole(claims *auth.Claims, roles []string) bool {
+	if len(roles) == 0 {
+		return true
+	}
+
+	for _, role := range roles {
+		if claims.HasRole(role) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func hasAllScopes(claims *auth.Claims, scopes []string) bool {
+	for _, scope := range scopes {
+		if !claims.HasScope(scope) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+
+	"github.com/helderfarias/go-api-kit/auth"
+	"github.com/helderfarias/go-api-kit/endpoint"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+type documentRequest struct {
+	Owner string
+}
+
+func TestAuthorizeByRolesAndScopes(t *testing.T) {
+	ctx := auth.ContextWithClaims(context.Background(), &auth.Claims{Roles: []string{"admin"}, Scope: "read write"})
+
+	service := func(ctx context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+		return endpoint.Response(200, "ok"), nil
+	}
+
+	allowed, _ := Authorize("documents", AuthorizeOptions{Roles: []string{"user", "admin"}, Scopes: []string{"read"}})(service)(ctx, "request")
+	denied, _ := Authorize("documents", AuthorizeOptions{Scopes: []string{"delete"}})(service)(ctx, "request")
+	anonymous, _ := Authorize("documents")(service)(nil, "request")
+
+	assert.Equal(t, 200, allowed.Code())
+	assert.Equal(t, 403, denied.Code())
+	assert.Equal(t, 401, anonymous.Code())
+}
+
+func TestAuthorizeWithOwnerPolicy(t *testing.T) {
+	ctx := auth.ContextWithClaims(context.Background(), &auth.Claims{Subject: "john"})
+
+	mw := Authorize("documents", AuthorizeOptions{
+		Policy: AnyPolicy(RolePolicy("admin"), OwnerPolicy(func(request interface{}) string {
+			return request.(*documentRequest).Owner
+		})),
+	})(func(ctx context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+		return endpoint.Response(200, "ok"), nil
+	})
+
+	own, _ := mw(ctx, &documentRequest{Owner: "john"})
+	other, _ := mw(ctx, &documentRequest{Owner: "mary"})
+
+	assert.Equal(t, 200, own.Code())
+	assert.Equal(t, 403, other.Code())
+}
+
+func TestAuthorizeRolesFromConfig(t *testing.T) {
+	viper.Set("authorization_reports_roles", []string{"auditor"})
+	defer viper.Set("authorization_reports_roles", nil)
+
+	ctx := auth.ContextWithClaims(context.Background(), &auth.Claims{Roles: []string{"admin"}})
+
+	resp, _ := Authorize("reports", AuthorizeOptions{Roles: []string{"admin"}})(func(ctx context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+		return endpoint.Response(200, "ok"), nil
+	})(ctx, "request")
+
+	assert.Equal(t, 403, resp.Code())
+}
@@ -0,0 +1,331 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/helderfarias/go-api-kit/cache"
+	"github.com/helderfarias/go-api-kit/endpoint"
+	"github.com/sirupsen/logrus"
+)
+
+// BreakerState state of the circuit breaker
+type BreakerState int
+
+const (
+	// StateClosed requests flow normally and failures are counted
+	StateClosed BreakerState = iota
+	// StateOpen requests fail fast until the cool-down elapses
+	StateOpen
+	// StateHalfOpen a limited number of requests probe the downstream
+	StateHalfOpen
+)
+
+// ErrCircuitOpen returned in the response data when the breaker rejects a request
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitBreakerOptions circuit breaker configurations
+type CircuitBreakerOptions struct {
+	// MaxConsecutiveFailures trips the breaker after N failures in a row, zero disables it
+	MaxConsecutiveFailures int
+	// FailureRateThreshold trips the breaker when failures/requests reaches the rate (0..1), zero disables it
+	FailureRateThreshold float64
+	// MinimumRequests required inside the window before the failure rate is evaluated
+	MinimumRequests int
+	// Window resets the closed state counters
+	Window time.Duration
+	// CoolDown time in open state before moving to half-open
+	CoolDown time.Duration
+	// HalfOpenMaxRequests probes allowed (and successes required) in half-open state
+	HalfOpenMaxRequests int
+	// Cache when set, the open state is shared through the cache server between replicas
+	Cache cache.CacheServer
+	// SyncInterval minimum time between reads of the shared state, defaults to 1s
+	SyncInterval  time.Duration
+	IsFailure     func(resp endpoint.EndpointResponse, err error) bool
+	OnStateChange func(name string, from, to BreakerState)
+}
+
+type breakerEntry struct {
+	State    BreakerState `json:"state"`
+	OpenedAt int64        `json:"openedAt"`
+}
+
+type circuitBreaker struct {
+	mu          sync.Mutex
+	name        string
+	opt         CircuitBreakerOptions
+	state       BreakerState
+	generation  uint64
+	openedAt    time.Time
+	windowStart time.Time
+	requests    int
+	failures    int
+	consecutive int
+	inFlight    int
+	successes   int
+	changes     []func()
+
+	syncMu   sync.Mutex
+	syncedAt time.Time
+	shared   *breakerEntry
+}
+
+// DefaultIsFailure errors and 5xx responses are failures
+var DefaultIsFailure = func(resp endpoint.EndpointResponse, err error) bool {
+	return err != nil || resp == nil || resp.Code() >= http.StatusInternalServerError
+}
+
+// CircuitBreaker fail fast when a downstream is unhealthy. The breaker starts closed, trips to open
+// when the consecutive failures or failure rate policy is reached, rejects requests with 503 during
+// the cool-down and then lets a few probes through (half-open) to decide whether to close again.
+func CircuitBreaker(name string, options ...CircuitBreakerOptions) endpoint.Middleware {
+	opt := CircuitBreakerOptions{}
+	if len(options) >= 1 {
+		opt = options[0]
+	}
+
+	if opt.MaxConsecutiveFailures == 0 && opt.FailureRateThreshold == 0 {
+		opt.MaxConsecutiveFailures = 5
+	}
+	if opt.MinimumRequests == 0 {
+		opt.MinimumRequests = 10
+	}
+	if opt.Window == 0 {
+		opt.Window = 60 * time.Second
+	}
+	if opt.CoolDown == 0 {
+		opt.CoolDown = 30 * time.Second
+	}
+	if opt.HalfOpenMaxRequests == 0 {
+		opt.HalfOpenMaxRequests = 1
+	}
+	if opt.SyncInterval == 0 {
+		opt.SyncInterval = time.Second
+	}
+	if opt.IsFailure == nil {
+		opt.IsFailure = DefaultIsFailure
+	}
+	if opt.OnStateChange == nil {
+		opt.OnStateChange = func(name string, from, to BreakerState) {}
+	}
+
+	cb := &circuitBreaker{name: name, opt: opt, windowStart: time.Now()}
+
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(parent context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+			generation, ok := cb.allow()
+			if !ok {
+				logrus.WithField("circuitbreaker.open", name).Debug("CircuitBreaker")
+				return endpoint.Response(http.StatusServiceUnavailable, map[string]string{"message": ErrCircuitOpen.Error()}), nil
+			}
+
+			failure := true
+			defer func() {
+				cb.record(generation, failure)
+			}()
+
+			resp, err := next(parent, request)
+			failure = opt.IsFailure(resp, err)
+
+			return resp, err
+		}
+	}
+}
+
+func (s BreakerState) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	}
+	return fmt.Sprintf("BreakerState(%d)", int(s))
+}
+
+func (cb *circuitBreaker) key() string {
+	return fmt.Sprintf("circuitbreaker:%v", cb.name)
+}
+
+func (cb *circuitBreaker) allow() (uint64, bool) {
+	shared := cb.sharedState()
+
+	cb.mu.Lock()
+	defer cb.flush()
+	defer cb.mu.Unlock()
+
+	now := time.Now()
+
+	if shared != nil && cb.state == StateClosed {
+		openedAt := time.Unix(0, shared.OpenedAt)
+		if now.Sub(openedAt) < cb.opt.CoolDown {
+			cb.setState(StateOpen)
+			cb.openedAt = openedAt
+		}
+	}
+
+	switch cb.state {
+	case StateOpen:
+		if now.Sub(cb.openedAt) < cb.opt.CoolDown {
+			return cb.generation, false
+		}
+		cb.setState(StateHalfOpen)
+	case StateClosed:
+		if now.Sub(cb.windowStart) >= cb.opt.Window {
+			cb.reset()
+		}
+		return cb.generation, true
+	}
+
+	if cb.inFlight >= cb.opt.HalfOpenMaxRequests {
+		return cb.generation, false
+	}
+
+	cb.inFlight++
+	return cb.generation, true
+}
+
+func (cb *circuitBreaker) record(generation uint64, failure bool) {
+	cb.mu.Lock()
+	defer cb.flush()
+	defer cb.mu.Unlock()
+
+	if generation != cb.generation {
+		return
+	}
+
+	switch cb.state {
+	case StateHalfOpen:
+		cb.inFlight--
+		if failure {
+			cb.trip()
+			return
+		}
+
+		cb.successes++
+		if cb.successes >= cb.opt.HalfOpenMaxRequests {
+			cb.setState(StateClosed)
+			cb.publish(nil)
+		}
+	case StateClosed:
+		cb.requests++
+		if !failure {
+			cb.consecutive = 0
+			return
+		}
+
+		cb.failures++
+		cb.consecutive++
+
+		if cb.opt.MaxConsecutiveFailures > 0 && cb.consecutive >= cb.opt.MaxConsecutiveFailures {
+			cb.trip()
+			return
+		}
+
+		if cb.opt.FailureRateThreshold > 0 && cb.requests >= cb.opt.MinimumRequests &&
+			float64(cb.failures)/float64(cb.requests) >= cb.opt.FailureRateThreshold {
+			cb.trip()
+		}
+	}
+}
+
+func (cb *circuitBreaker) trip() {
+	cb.setState(StateOpen)
+	cb.openedAt = time.Now()
+	cb.publish(&breakerEntry{State: StateOpen, OpenedAt: cb.openedAt.UnixNano()})
+}
+
+func (cb *circuitBreaker) setState(to BreakerState) {
+	from := cb.state
+	if from == to {
+		return
+	}
+
+	cb.state = to
+	cb.generation++
+	cb.reset()
+
+	cb.changes = append(cb.changes, func() {
+		logrus.WithField("circuitbreaker."+to.String(), cb.name).Info("CircuitBreaker")
+		cb.opt.OnStateChange(cb.name, from, to)
+	})
+}
+
+func (cb *circuitBreaker) reset() {
+	cb.windowStart = time.Now()
+	cb.requests = 0
+	cb.failures = 0
+	cb.consecutive = 0
+	cb.inFlight = 0
+	cb.successes = 0
+}
+
+// flush runs the state change callbacks outside the lock
+func (cb *circuitBreaker) flush() {
+	cb.mu.Lock()
+	changes := cb.changes
+	cb.changes = nil
+	cb.mu.Unlock()
+
+	for _, fire := range changes {
+		fire()
+	}
+}
+
+func (cb *circuitBreaker) publish(entry *breakerEntry) {
+	if cb.opt.Cache == nil {
+		return
+	}
+
+	cb.changes = append(cb.changes, func() {
+		if entry == nil {
+			if err := cb.opt.Cache.Delete(cb.key()); err != nil {
+				logrus.Error(err)
+			}
+			return
+		}
+
+		if err := cb.opt.Cache.Set(cb.key(), entry, cb.opt.CoolDown); err != nil {
+			logrus.Error(err)
+		}
+	})
+}
+
+// sharedState the open state published by the replicas, read at most once per SyncInterval
+func (cb *circuitBreaker) sharedState() *breakerEntry {
+	if cb.opt.Cache == nil {
+		return nil
+	}
+
+	cb.syncMu.Lock()
+	defer cb.syncMu.Unlock()
+
+	if time.Since(cb.syncedAt) < cb.opt.SyncInterval {
+		return cb.shared
+	}
+
+	cb.syncedAt = time.Now()
+	cb.shared = cb.fetchSharedState()
+
+	return cb.shared
+}
+
+func (cb *circuitBreaker) fetchSharedState() *breakerEntry {
+	var entry breakerEntry
+	cached, err := cb.opt.Cache.Get(cb.key(), &entry)
+	if err != nil {
+		return nil
+	}
+
+	if shared, ok := cached.(*breakerEntry); ok && shared.State == StateOpen {
+		return shared
+	}
+
+	return nil
+}
@@ -0,0 +1,142 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/helderfarias/go-api-kit/endpoint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCircuitBreakerOpenAfterConsecutiveFailures(t *testing.T) {
+	calls := 0
+	changes := []string{}
+
+	mw := CircuitBreaker("addresses", CircuitBreakerOptions{
+		MaxConsecutiveFailures: 2,
+		OnStateChange: func(name string, from, to BreakerState) {
+			changes = append(changes, from.String()+"->"+to.String())
+		},
+	})(func(ctx context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+		calls++
+		return nil, errors.New("downstream error")
+	})
+
+	mw(context.Background(), "request")
+	mw(context.Background(), "request")
+	resp, err := mw(context.Background(), "request")
+
+	assert.Nil(t, err)
+	assert.Equal(t, 503, resp.Code())
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, []string{"closed->open"}, changes)
+}
+
+func TestCircuitBreakerCloseAfterHalfOpenSuccess(t *testing.T) {
+	fail := true
+	changes := []string{}
+
+	mw := CircuitBreaker("addresses", CircuitBreakerOptions{
+		MaxConsecutiveFailures: 1,
+		CoolDown:               10 * time.Millisecond,
+		OnStateChange: func(name string, from, to BreakerState) {
+			changes = append(changes, from.String()+"->"+to.String())
+		},
+	})(func(ctx context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+		if fail {
+			return endpoint.Response(500, nil), nil
+		}
+		return endpoint.Response(200, "ok"), nil
+	})
+
+	mw(context.Background(), "request")
+	fail = false
+	time.Sleep(20 * time.Millisecond)
+	resp, err := mw(context.Background(), "request")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "ok", resp.Data())
+	assert.Equal(t, []string{"closed->open", "open->half-open", "half-open->closed"}, changes)
+}
+
+func TestCircuitBreakerOpenByFailureRate(t *testing.T) {
+	i := 0
+
+	mw := CircuitBreaker("addresses", CircuitBreakerOptions{
+		FailureRateThreshold: 0.5,
+		MinimumRequests:      4,
+	})(func(ctx context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+		i++
+		if i%2 == 0 {
+			return nil, errors.New("downstream error")
+		}
+		return endpoint.Response(200, "ok"), nil
+	})
+
+	for n := 0; n < 4; n++ {
+		mw(context.Background(), "request")
+	}
+	resp, _ := mw(context.Background(), "request")
+
+	assert.Equal(t, 503, resp.Code())
+}
+
+func TestCircuitBreakerSharedStateFromCache(t *testing.T) {
+	cacheMock := &cacheServerMock{}
+
+	cacheMock.On("Get", "circuitbreaker:addresses", mock.Anything).Return(&breakerEntry{State: StateOpen, OpenedAt: time.Now().UnixNano()}, nil)
+
+	mw := CircuitBreaker("addresses", CircuitBreakerOptions{Cache: cacheMock})(func(ctx context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+		return endpoint.Response(200, "not return"), nil
+	})
+
+	resp, err := mw(context.Background(), "request")
+
+	assert.Nil(t, err)
+	assert.Equal(t, 503, resp.Code())
+	cacheMock.AssertExpectations(t)
+}
+
+func TestCircuitBreakerPanicReleasesHalfOpenProbe(t *testing.T) {
+	panics := true
+
+	mw := CircuitBreaker("addresses", CircuitBreakerOptions{
+		MaxConsecutiveFailures: 1,
+		CoolDown:               10 * time.Millisecond,
+	})(func(ctx context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+		if panics {
+			panic("boom")
+		}
+		return endpoint.Response(200, "ok"), nil
+	})
+
+	assert.Panics(t, func() { mw(context.Background(), "request") })
+	time.Sleep(20 * time.Millisecond)
+	assert.Panics(t, func() { mw(context.Background(), "request") })
+
+	panics = false
+	time.Sleep(20 * time.Millisecond)
+	resp, err := mw(context.Background(), "request")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "ok", resp.Data())
+}
+
+func TestCircuitBreakerSharedStateReadOncePerSyncInterval(t *testing.T) {
+	cacheMock := &cacheServerMock{}
+
+	cacheMock.On("Get", "circuitbreaker:addresses", mock.Anything).Return(nil, errors.New("not found")).Once()
+
+	mw := CircuitBreaker("addresses", CircuitBreakerOptions{Cache: cacheMock, SyncInterval: time.Minute})(func(ctx context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+		return endpoint.Response(200, "ok"), nil
+	})
+
+	for n := 0; n < 3; n++ {
+		mw(context.Background(), "request")
+	}
+
+	cacheMock.AssertNumberOfCalls(t, "Get", 1)
+}
@@ -0,0 +1,277 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/helderfarias/go-api-kit/endpoint"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrBulkheadFull returned in the response data when the bulkhead rejects a request
+var ErrBulkheadFull = errors.New("too many concurrent requests")
+
+// ConcurrencyLimit strategy used by the bulkhead to decide the max in-flight requests
+type ConcurrencyLimit interface {
+	Limit() int
+
+	// OnSample is called after every request with its latency, the in-flight
+	// requests when it started and whether it was dropped (error or timeout)
+	OnSample(latency time.Duration, inFlight int, dropped bool)
+}
+
+// BulkheadOptions bulkhead configurations
+type BulkheadOptions struct {
+	// MaxConcurrent fixed limit of in-flight requests, ignored when Limit is set
+	MaxConcurrent int
+	// MaxQueue requests waiting for a slot, beyond it requests are rejected
+	MaxQueue int
+	// QueueTimeout max time waiting in the queue, zero waits until the context is done
+	QueueTimeout time.Duration
+	// Limit adaptive strategy, see AIMDLimit and GradientLimit
+	Limit      ConcurrencyLimit
+	OnListener func(event string, name string)
+}
+
+type bulkhead struct {
+	mu       sync.Mutex
+	limit    ConcurrencyLimit
+	inFlight int
+	waiters  []chan struct{}
+}
+
+type fixedLimit struct {
+	limit int
+}
+
+type aimdLimit struct {
+	mu        sync.Mutex
+	limit     float64
+	min       float64
+	max       float64
+	threshold time.Duration
+	backoff   float64
+}
+
+type gradientLimit struct {
+	mu       sync.Mutex
+	limit    float64
+	min      float64
+	max      float64
+	minRTT   float64
+	smoothed float64
+}
+
+// Bulkhead isolates an endpoint by limiting the requests in flight (e.g. to protect the
+// database pool). Requests beyond the limit wait in a bounded queue and are rejected with 503
+// when the queue is full or the wait times out.
+func Bulkhead(name string, options ...BulkheadOptions) endpoint.Middleware {
+	opt := BulkheadOptions{}
+	if len(options) >= 1 {
+		opt = options[0]
+	}
+
+	if opt.MaxConcurrent == 0 {
+		opt.MaxConcurrent = 10
+	}
+	if opt.Limit == nil {
+		opt.Limit = FixedLimit(opt.MaxConcurrent)
+	}
+	if opt.OnListener == nil {
+		opt.OnListener = DefaultListener
+	}
+
+	b := &bulkhead{limit: opt.Limit}
+
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(parent context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+			inFlight, ok := b.acquire(parent, opt.MaxQueue, opt.QueueTimeout, func() {
+				opt.OnListener("queued", name)
+			})
+			if !ok {
+				logrus.WithField("bulkhead.rejected", name).Debug("Bulkhead")
+				opt.OnListener("rejected", name)
+				return endpoint.Response(http.StatusServiceUnavailable, map[string]string{"message": ErrBulkheadFull.Error()}), nil
+			}
+
+			start := time.Now()
+			defer b.release()
+
+			resp, err := next(parent, request)
+
+			b.limit.OnSample(time.Since(start), inFlight, err != nil)
+
+			return resp, err
+		}
+	}
+}
+
+// FixedLimit static concurrency limit
+func FixedLimit(limit int) ConcurrencyLimit {
+	return &fixedLimit{limit: limit}
+}
+
+// AIMDLimit additive increase/multiplicative decrease, the limit grows by one while the
+// latency stays under threshold and is multiplied by backoff (e.g. 0.9) otherwise.
+// min is at least 1, a zero limit would never release a slot again.
+func AIMDLimit(initial, min, max int, threshold time.Duration, backoff float64) ConcurrencyLimit {
+	lower, upper, limit := limitBounds(initial, min, max)
+	return &aimdLimit{limit: limit, min: lower, max: upper, threshold: threshold, backoff: backoff}
+}
+
+// GradientLimit adjusts the limit by the ratio between the best and the current latency,
+// shrinking when the latency grows (queueing) and probing upwards when it stays stable.
+// min is at least 1, a zero limit would never release a slot again.
+func GradientLimit(initial, min, max int) ConcurrencyLimit {
+	lower, upper, limit := limitBounds(initial, min, max)
+	return &gradientLimit{limit: limit, min: lower, max: upper}
+}
+
+// limitBounds clamps min to 1, max to min and initial between them
+func limitBounds(initial, min, max int) (float64, float64, float64) {
+	lower := math.Max(1, float64(min))
+	upper := math.Max(lower, float64(max))
+	return lower, upper, math.Max(lower, math.Min(upper, float64(initial)))
+}
+
+// acquire takes a slot, the queued requests are served first (FIFO)
+func (b *bulkhead) acquire(parent context.Context, maxQueue int, timeout time.Duration, onQueued func()) (int, bool) {
+	b.mu.Lock()
+	if len(b.waiters) == 0 && b.inFlight < b.limit.Limit() {
+		b.inFlight++
+		inFlight := b.inFlight
+		b.mu.Unlock()
+		return inFlight, true
+	}
+
+	if len(b.waiters) >= maxQueue {
+		b.mu.Unlock()
+		return 0, false
+	}
+
+	ready := make(chan struct{})
+	b.waiters = append(b.waiters, ready)
+	b.mu.Unlock()
+
+	onQueued()
+
+	if parent == nil {
+		parent = context.Background()
+	}
+
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	select {
+	case <-ready:
+		return b.current(), true
+	case <-parent.Done():
+	case <-expired:
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for i, w := range b.waiters {
+		if w == ready {
+			b.waiters = append(b.waiters[:i], b.waiters[i+1:]...)
+			return 0, false
+		}
+	}
+
+	// the slot was granted while giving up, hand it to the next waiter
+	b.inFlight--
+	b.dispatch()
+	return 0, false
+}
+
+func (b *bulkhead) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.inFlight--
+	b.dispatch()
+}
+
+func (b *bulkhead) dispatch() {
+	for len(b.waiters) > 0 && b.inFlight < b.limit.Limit() {
+		ready := b.waiters[0]
+		b.waiters = b.waiters[1:]
+		b.inFlight++
+		close(ready)
+	}
+}
+
+func (b *bulkhead) current() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.inFlight
+}
+
+func (l *fixedLimit) Limit() int {
+	return l.limit
+}
+
+func (l *fixedLimit) OnSample(latency time.Duration, inFlight int, dropped bool) {}
+
+func (l *aimdLimit) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return int(l.limit)
+}
+
+func (l *aimdLimit) OnSample(latency time.Duration, inFlight int, dropped bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if dropped || latency > l.threshold {
+		l.limit = math.Max(l.min, math.Floor(l.limit*l.backoff))
+		return
+	}
+
+	if float64(inFlight)*2 >= l.limit {
+		l.limit = math.Min(l.max, l.limit+1)
+	}
+}
+
+func (l *gradientLimit) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return int(l.limit)
+}
+
+func (l *gradientLimit) OnSample(latency time.Duration, inFlight int, dropped bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	rtt := float64(latency)
+	if rtt <= 0 {
+		return
+	}
+
+	if l.smoothed == 0 {
+		l.smoothed = rtt
+	} else {
+		l.smoothed = l.smoothed*0.9 + rtt*0.1
+	}
+
+	if l.minRTT == 0 || rtt < l.minRTT {
+		l.minRTT = rtt
+	}
+
+	gradient := math.Max(0.5, math.Min(1, l.minRTT/l.smoothed))
+	if dropped {
+		gradient = 0.5
+	}
+
+	limit := l.limit*gradient + math.Sqrt(l.limit)
+	l.limit = math.Max(l.min, math.Min(l.max, l.limit*0.8+limit*0.2))
+}
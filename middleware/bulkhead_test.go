@@ -0,0 +1,123 @@
+package middleware
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/helderfarias/go-api-kit/endpoint"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBulkheadRejectWhenQueueIsFull(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{})
+
+	mw := Bulkhead("db", BulkheadOptions{MaxConcurrent: 1})(func(ctx context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+		close(started)
+		<-release
+		return endpoint.Response(200, "ok"), nil
+	})
+
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		mw(context.Background(), "first")
+	}()
+
+	<-started
+	resp, err := mw(context.Background(), "second")
+	close(release)
+	wg.Wait()
+
+	assert.Nil(t, err)
+	assert.Equal(t, 503, resp.Code())
+}
+
+func TestBulkheadWaitInQueue(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{})
+	queued := make(chan struct{}, 1)
+
+	mw := Bulkhead("db", BulkheadOptions{
+		MaxConcurrent: 1,
+		MaxQueue:      1,
+		QueueTimeout:  time.Second,
+		OnListener: func(event string, name string) {
+			if event == "queued" {
+				queued <- struct{}{}
+			}
+		},
+	})(func(ctx context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+		if request == "first" {
+			close(started)
+			<-release
+		}
+		return endpoint.Response(200, request), nil
+	})
+
+	go mw(context.Background(), "first")
+	<-started
+
+	second := make(chan endpoint.EndpointResponse)
+	go func() {
+		resp, _ := mw(context.Background(), "second")
+		second <- resp
+	}()
+
+	<-queued
+	close(release)
+
+	assert.Equal(t, "second", (<-second).Data())
+}
+
+func TestBulkheadQueuedRequestsGoFirst(t *testing.T) {
+	b := &bulkhead{limit: FixedLimit(1)}
+
+	_, ok := b.acquire(context.Background(), 1, 0, func() {})
+	assert.True(t, ok)
+
+	queued := make(chan struct{})
+	granted := make(chan bool)
+	go func() {
+		_, ok := b.acquire(context.Background(), 1, 0, func() { close(queued) })
+		granted <- ok
+	}()
+	<-queued
+
+	// a slot frees up before the queue is dispatched, e.g. the adaptive limit grew
+	b.mu.Lock()
+	b.inFlight--
+	b.mu.Unlock()
+
+	_, ok = b.acquire(context.Background(), 0, 0, func() {})
+	assert.False(t, ok)
+
+	b.release()
+	assert.True(t, <-granted)
+}
+
+func TestAIMDLimitBackoffOnSlowRequests(t *testing.T) {
+	limit := AIMDLimit(10, 1, 20, 100*time.Millisecond, 0.5)
+
+	limit.OnSample(200*time.Millisecond, 1, false)
+	assert.Equal(t, 5, limit.Limit())
+
+	limit.OnSample(time.Millisecond, 5, false)
+	assert.Equal(t, 6, limit.Limit())
+}
+
+func TestAdaptiveLimitsNeverReachZero(t *testing.T) {
+	aimd := AIMDLimit(1, 0, 10, time.Millisecond, 0.5)
+	gradient := GradientLimit(1, 0, 10)
+
+	for n := 0; n < 10; n++ {
+		aimd.OnSample(time.Second, 1, true)
+		gradient.OnSample(time.Second, 1, true)
+	}
+
+	assert.Equal(t, 1, aimd.Limit())
+	assert.Equal(t, 1, gradient.Limit())
+}
@@ -5,8 +5,10 @@ import (
 	"crypto/md5"
 	"encoding/hex"
 	"fmt"
+	"net/http"
 	"time"
 
+	"github.com/helderfarias/go-api-kit/apierror"
 	"github.com/helderfarias/go-api-kit/cache"
 	"github.com/helderfarias/go-api-kit/endpoint"
 	"github.com/sirupsen/logrus"
@@ -25,6 +27,8 @@ type CacheableOptions struct {
 	TTL          time.Duration
 	OnListener   func(event string, key string)
 	KeyGenerator func(name string, args interface{}) string
+	// ETag stores the ETag of the value with the entry, reused by Conditional on hits
+	ETag bool
 }
 
 // CachePutOptions cache configurations
@@ -34,10 +38,12 @@ type CachePutOptions struct {
 	KeyGenerator func(name string, args interface{}) string
 }
 
-// EntryCache cache container
+// EntryCache cache container, cookies and Set-Cookie are never stored since the entry is
+// replayed to every client
 type EntryCache struct {
-	Status int         `json:"status"`
-	Value  interface{} `json:"value"`
+	Status  int         `json:"status"`
+	Value   interface{} `json:"value"`
+	Headers http.Header `json:"headers,omitempty"`
 }
 
 // DefaultListener listener
@@ -117,10 +123,10 @@ func CachePut(cache cache.CacheServer, name string, options ...CachePutOptions)
 
 			resp, err := next(parent, request)
 
-			if err == nil && resp.Data() != nil && resp.Code() < 400 {
+			if isCacheable(resp, err) {
 				key := opt.KeyGenerator(name, request)
 
-				newEntry := EntryCache{Status: resp.Code(), Value: resp.Data()}
+				newEntry := newEntryCache(resp)
 
 				if err := cache.Set(key, newEntry, opt.TTL); err != nil {
 					logrus.Error(err)
@@ -136,7 +142,8 @@ func CachePut(cache cache.CacheServer, name string, options ...CachePutOptions)
 }
 
 // Cacheable The simplest way to enable caching behavior for a method is to demarcate it
-// with Cacheable and parameterize it with the name of the cache where the results would be stored
+// with Cacheable and parameterize it with the name of the cache where the results would be stored.
+// Cookies of the response are sent only to the client of the miss, hits carry none.
 func Cacheable(cache cache.CacheServer, name string, options ...CacheableOptions) endpoint.Middleware {
 	return func(next endpoint.Endpoint) endpoint.Endpoint {
 		return func(parent context.Context, request interface{}) (endpoint.EndpointResponse, error) {
@@ -162,13 +169,19 @@ func Cacheable(cache cache.CacheServer, name string, options ...CacheableOptions
 			} else if cache, ok := cached.(*EntryCache); ok && cache.Value != nil {
 				logrus.WithField("cacheable.get", key).Debug("Cacheable")
 				opt.OnListener("get", key)
-				return endpoint.Response(cache.Status, cache.Value), nil
+				return endpoint.ResponseWithMetadata(cache.Status, cache.Value, cache.Headers), nil
 			}
 
 			resp, err := next(parent, request)
 
-			if err == nil && resp.Data() != nil && resp.Code() < 400 {
-				newEntry := EntryCache{Status: resp.Code(), Value: resp.Data()}
+			if isCacheable(resp, err) {
+				if opt.ETag && endpoint.HeadersOf(resp).Get("ETag") == "" {
+					if tag, err := ETag(resp.Data(), false); err == nil {
+						resp = endpoint.WithHeader(resp, "ETag", tag)
+					}
+				}
+
+				newEntry := newEntryCache(resp)
 
 				if err := cache.Set(key, newEntry, opt.TTL); err != nil {
 					logrus.Error(err)
@@ -182,3 +195,30 @@ func Cacheable(cache cache.CacheServer, name string, options ...CacheableOptions
 		}
 	}
 }
+
+func newEntryCache(resp endpoint.EndpointResponse) EntryCache {
+	entry := EntryCache{Status: resp.Code(), Value: resp.Data()}
+
+	headers := http.Header{}
+	for k, v := range endpoint.HeadersOf(resp) {
+		if k != "Set-Cookie" {
+			headers[k] = v
+		}
+	}
+
+	if len(headers) > 0 {
+		entry.Headers = headers
+	}
+
+	return entry
+}
+
+// isCacheable only successful responses are cached, never errors nor problem details
+func isCacheable(resp endpoint.EndpointResponse, err error) bool {
+	if err != nil || resp == nil || resp.Data() == nil || resp.Code() >= 400 {
+		return false
+	}
+
+	_, problem := resp.Data().(apierror.Problem)
+	return !problem
+}
@@ -3,9 +3,11 @@ package middleware
 import (
 	"context"
 	"errors"
+	"net/http"
 	"testing"
 	"time"
 
+	"github.com/helderfarias/go-api-kit/apierror"
 	"github.com/helderfarias/go-api-kit/endpoint"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -88,7 +90,7 @@ func TestDeleteCacheWhenCachePutIfServiceResultError(t *testing.T) {
 func TestValueFromCacheWhenNotEmpty(t *testing.T) {
 	cacheMock := &cacheServerMock{}
 
-	cacheMock.On("Get", "addresses:bc2b9fed1ade259444436fb721d3ab22", mock.Anything).Return(&EntryCache{200, "cached: address 10"}, nil)
+	cacheMock.On("Get", "addresses:bc2b9fed1ade259444436fb721d3ab22", mock.Anything).Return(&EntryCache{Status: 200, Value: "cached: address 10"}, nil)
 
 	mw := Cacheable(cacheMock, "addresses")(func(ctx context.Context, request interface{}) (endpoint.EndpointResponse, error) {
 		return endpoint.Response(200, "not return"), nil
@@ -149,3 +151,73 @@ func TestShouldNotPutValueToCacheWhenEmptyIfResponseNil(t *testing.T) {
 	assert.Nil(t, resp)
 	cacheMock.AssertExpectations(t)
 }
+
+func TestShouldNotPutProblemToCache(t *testing.T) {
+	cacheMock := &cacheServerMock{}
+
+	cacheMock.On("Get", "addresses:8a80b0b2fc5b41f18697478e5031ca22", mock.Anything).Return(nil, nil)
+
+	mw := Cacheable(cacheMock, "addresses")(func(ctx context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+		return nil, apierror.NotFound("address not found")
+	})
+
+	resp, err := mw(nil, "params")
+
+	assert.EqualError(t, err, "address not found")
+	assert.Nil(t, resp)
+	cacheMock.AssertNotCalled(t, "Set", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPutHeadersToCache(t *testing.T) {
+	cacheMock := &cacheServerMock{}
+
+	cacheMock.On("Get", "addresses:8a80b0b2fc5b41f18697478e5031ca22", mock.Anything).Return(nil, nil)
+	cacheMock.On("Set", "addresses:8a80b0b2fc5b41f18697478e5031ca22", mock.MatchedBy(func(entry EntryCache) bool {
+		return entry.Headers.Get("Cache-Control") == "max-age=60"
+	}), mock.Anything).Return(nil)
+
+	mw := Cacheable(cacheMock, "addresses")(func(ctx context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+		return endpoint.WithCacheControl(endpoint.Response(200, "cached"), "max-age=60"), nil
+	})
+
+	_, err := mw(nil, "params")
+
+	assert.Nil(t, err)
+	cacheMock.AssertExpectations(t)
+}
+
+func TestShouldNotPutCookiesToCache(t *testing.T) {
+	cacheMock := &cacheServerMock{}
+
+	cacheMock.On("Get", "addresses:8a80b0b2fc5b41f18697478e5031ca22", mock.Anything).Return(nil, nil)
+	cacheMock.On("Set", "addresses:8a80b0b2fc5b41f18697478e5031ca22", mock.MatchedBy(func(entry EntryCache) bool {
+		return entry.Headers.Get("Set-Cookie") == "" && entry.Headers.Get("Cache-Control") == "max-age=60"
+	}), mock.Anything).Return(nil)
+
+	mw := Cacheable(cacheMock, "addresses")(func(ctx context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+		resp := endpoint.WithCacheControl(endpoint.Response(200, "cached"), "max-age=60")
+		resp = endpoint.WithHeader(resp, "Set-Cookie", "session=1")
+		return endpoint.WithCookie(resp, &http.Cookie{Name: "session", Value: "1"}), nil
+	})
+
+	resp, err := mw(nil, "params")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "session", endpoint.CookiesOf(resp)[0].Name)
+	cacheMock.AssertExpectations(t)
+}
+
+func TestValueAndHeadersFromCache(t *testing.T) {
+	cacheMock := &cacheServerMock{}
+
+	cacheMock.On("Get", "addresses:bc2b9fed1ade259444436fb721d3ab22", mock.Anything).Return(&EntryCache{Status: 200, Value: "cached", Headers: http.Header{"Etag": []string{`"1"`}}}, nil)
+
+	mw := Cacheable(cacheMock, "addresses")(func(ctx context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+		return endpoint.Response(200, "not return"), nil
+	})
+
+	resp, err := mw(nil, "request")
+
+	assert.Nil(t, err)
+	assert.Equal(t, `"1"`, endpoint.HeadersOf(resp).Get("ETag"))
+}
@@ -0,0 +1,224 @@
+package middleware
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/helderfarias/go-api-kit/endpoint"
+	"github.com/sirupsen/logrus"
+)
+
+// ConditionalOptions conditional requests configurations
+type ConditionalOptions struct {
+	// WeakETag generates W/"..." validators, for representations that are
+	// semantically equivalent but not byte identical
+	WeakETag bool
+	// CurrentETag ETag of the resource before an update, enables If-Match (412)
+	CurrentETag func(ctx context.Context, request interface{}) (string, error)
+	// MediaType media type negotiated for the request, e.g. httptransport.DefaultCodecs.NegotiatedMediaType,
+	// the ETags vary by it when set
+	MediaType func(r *http.Request) string
+}
+
+// ETag validator of the data, the md5 of its JSON representation
+func ETag(data interface{}, weak bool) (string, error) {
+	enc, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+
+	sum := md5.Sum(enc)
+	tag := fmt.Sprintf("%q", hex.EncodeToString(sum[:]))
+	if weak {
+		return "W/" + tag, nil
+	}
+
+	return tag, nil
+}
+
+// Conditional handles conditional requests. Successful GET/HEAD responses get an ETag (computed
+// from Data unless the endpoint or Cacheable already set one) and are replaced by 304 when
+// If-None-Match or If-Modified-Since (against the Last-Modified header) match. Updates with
+// If-Match are rejected with 412 when CurrentETag doesn't match.
+//
+// The same data can be negotiated as JSON, XML, CSV..., with MediaType each representation
+// gets its own validator: the media type is appended to the tag ("<tag>-<suffix>") and
+// Vary: Accept is set. If-Match ignores the suffix, an update applies to every representation.
+func Conditional(options ...ConditionalOptions) endpoint.Middleware {
+	opt := ConditionalOptions{}
+	if len(options) >= 1 {
+		opt = options[0]
+	}
+
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(parent context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+			r, ok := endpoint.RequestFromContext(parent)
+			if !ok {
+				return next(parent, request)
+			}
+
+			if r.Method != http.MethodGet && r.Method != http.MethodHead {
+				ifMatch := r.Header.Get("If-Match")
+				if ifMatch == "" || opt.CurrentETag == nil {
+					return next(parent, request)
+				}
+
+				current, err := opt.CurrentETag(parent, request)
+				if err != nil {
+					return nil, err
+				}
+
+				if !matchETag(ifMatch, current, false) {
+					logrus.WithField("conditional.precondition", current).Debug("Conditional")
+					return endpoint.Response(http.StatusPreconditionFailed, map[string]string{"message": "precondition failed"}), nil
+				}
+
+				return next(parent, request)
+			}
+
+			resp, err := next(parent, request)
+			if err != nil || resp == nil || resp.Code() < 200 || resp.Code() >= 300 || resp.Data() == nil {
+				return resp, err
+			}
+
+			headers := endpoint.HeadersOf(resp)
+
+			tag := headers.Get("ETag")
+			if tag == "" {
+				tag, err = ETag(resp.Data(), opt.WeakETag)
+				if err != nil {
+					logrus.Error(err)
+					return resp, nil
+				}
+			}
+
+			if opt.MediaType != nil {
+				tag = representationETag(tag, opt.MediaType(r))
+				resp = endpoint.WithHeader(resp, "Vary", varyAccept(headers.Get("Vary")))
+			}
+			if tag != headers.Get("ETag") {
+				resp = endpoint.WithHeader(resp, "ETag", tag)
+			}
+
+			if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" {
+				if matchETag(ifNoneMatch, tag, true) {
+					return notModified(resp), nil
+				}
+				return resp, nil
+			}
+
+			if notModifiedSince(r.Header.Get("If-Modified-Since"), headers.Get("Last-Modified")) {
+				return notModified(resp), nil
+			}
+
+			return resp, nil
+		}
+	}
+}
+
+// LastModified sets the Last-Modified header used by If-Modified-Since
+func LastModified(resp endpoint.EndpointResponse, t time.Time) endpoint.MetadataResponse {
+	return endpoint.WithHeader(resp, "Last-Modified", t.UTC().Format(http.TimeFormat))
+}
+
+func notModified(resp endpoint.EndpointResponse) endpoint.EndpointResponse {
+	headers := http.Header{}
+	for _, key := range []string{"ETag", "Cache-Control", "Last-Modified", "Expires", "Vary"} {
+		if v := endpoint.HeadersOf(resp).Get(key); v != "" {
+			headers.Set(key, v)
+		}
+	}
+
+	return endpoint.ResponseWithMetadata(http.StatusNotModified, nil, headers)
+}
+
+// representationETag appends a suffix of the media type to the tag, "abc" => "abc-1a2b3c4d"
+func representationETag(tag, mediaType string) string {
+	if mediaType == "" || !strings.HasSuffix(tag, `"`) {
+		return tag
+	}
+
+	sum := md5.Sum([]byte(strings.ToLower(mediaType)))
+	return strings.TrimSuffix(tag, `"`) + "-" + hex.EncodeToString(sum[:4]) + `"`
+}
+
+// baseETag the tag without the suffix added by representationETag
+func baseETag(tag string) string {
+	// -<8 hex digits>"
+	const suffix = 10
+	if len(tag) < suffix+2 || tag[len(tag)-suffix] != '-' {
+		return tag
+	}
+
+	if _, err := hex.DecodeString(tag[len(tag)-suffix+1 : len(tag)-1]); err != nil {
+		return tag
+	}
+
+	return tag[:len(tag)-suffix] + `"`
+}
+
+// varyAccept adds Accept to the Vary header
+func varyAccept(vary string) string {
+	for _, v := range strings.Split(vary, ",") {
+		if strings.EqualFold(strings.TrimSpace(v), "Accept") || strings.TrimSpace(v) == "*" {
+			return vary
+		}
+	}
+
+	if strings.TrimSpace(vary) == "" {
+		return "Accept"
+	}
+	return vary + ", Accept"
+}
+
+// matchETag compares the header list against the tag, weak comparison ignores the W/ prefix.
+// Strong comparison also accepts the representations of the tag ("<tag>-<suffix>").
+func matchETag(header, tag string, weak bool) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+
+		if weak {
+			if strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(tag, "W/") {
+				return true
+			}
+			continue
+		}
+
+		if strings.HasPrefix(candidate, "W/") || strings.HasPrefix(tag, "W/") {
+			continue
+		}
+
+		if candidate == tag || baseETag(candidate) == tag {
+			return true
+		}
+	}
+
+	return false
+}
+
+func notModifiedSince(ifModifiedSince, lastModified string) bool {
+	if ifModifiedSince == "" || lastModified == "" {
+		return false
+	}
+
+	since, err := http.ParseTime(ifModifiedSince)
+	if err != nil {
+		return false
+	}
+
+	modified, err := http.ParseTime(lastModified)
+	if err != nil {
+		return false
+	}
+
+	return !modified.After(since)
+}
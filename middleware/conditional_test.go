@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/helderfarias/go-api-kit/endpoint"
+	"github.com/helderfarias/go-api-kit/httptransport"
+	"github.com/stretchr/testify/assert"
+)
+
+func serveConditional(e endpoint.Endpoint, method string, headers map[string]string) *httptest.ResponseRecorder {
+	handler := httptransport.NewServer(e, httptransport.NopRequestDecoder, httptransport.EncodeJSONResponse)
+
+	req := httptest.NewRequest(method, "/addresses/1", nil)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestConditionalIfNoneMatch(t *testing.T) {
+	mw := Conditional()(func(ctx context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+		return endpoint.Response(200, "address"), nil
+	})
+
+	first := serveConditional(mw, "GET", nil)
+	tag := first.Header().Get("ETag")
+
+	second := serveConditional(mw, "GET", map[string]string{"If-None-Match": "W/" + tag})
+
+	assert.Equal(t, 200, first.Code)
+	assert.NotEmpty(t, tag)
+	assert.Equal(t, 304, second.Code)
+	assert.Equal(t, tag, second.Header().Get("ETag"))
+	assert.Empty(t, second.Body.String())
+}
+
+func TestConditionalIfModifiedSince(t *testing.T) {
+	modified := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	mw := Conditional()(func(ctx context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+		return LastModified(endpoint.Response(200, "address"), modified), nil
+	})
+
+	notModified := serveConditional(mw, "GET", map[string]string{"If-Modified-Since": modified.Add(time.Hour).Format(http.TimeFormat)})
+	modifiedSince := serveConditional(mw, "GET", map[string]string{"If-Modified-Since": modified.Add(-time.Hour).Format(http.TimeFormat)})
+
+	assert.Equal(t, 304, notModified.Code)
+	assert.Equal(t, 200, modifiedSince.Code)
+}
+
+func TestConditionalIfMatchPreconditionFailed(t *testing.T) {
+	current, _ := ETag("address", false)
+	updated := false
+
+	mw := Conditional(ConditionalOptions{
+		CurrentETag: func(ctx context.Context, request interface{}) (string, error) {
+			return current, nil
+		},
+	})(func(ctx context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+		updated = true
+		return endpoint.Response(204, nil), nil
+	})
+
+	failed := serveConditional(mw, "PUT", map[string]string{"If-Match": `"stale"`})
+	assert.Equal(t, 412, failed.Code)
+	assert.False(t, updated)
+
+	ok := serveConditional(mw, "PUT", map[string]string{"If-Match": current})
+	assert.Equal(t, 204, ok.Code)
+	assert.True(t, updated)
+}
+
+func TestConditionalETagPerRepresentation(t *testing.T) {
+	current, _ := ETag("address", false)
+
+	mw := Conditional(ConditionalOptions{
+		CurrentETag: func(ctx context.Context, request interface{}) (string, error) {
+			return current, nil
+		},
+		MediaType: httptransport.DefaultCodecs.NegotiatedMediaType,
+	})(func(ctx context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+		if r, _ := endpoint.RequestFromContext(ctx); r.Method == "PUT" {
+			return endpoint.Response(204, nil), nil
+		}
+		return endpoint.Response(200, "address"), nil
+	})
+
+	first := serveConditional(mw, "GET", map[string]string{"Accept": "application/json"})
+	json := first.Header().Get("ETag")
+	xml := serveConditional(mw, "GET", map[string]string{"Accept": "application/xml"}).Header().Get("ETag")
+
+	assert.Equal(t, json, serveConditional(mw, "GET", nil).Header().Get("ETag"))
+	assert.Equal(t, json, serveConditional(mw, "GET", map[string]string{"Accept": "*/*"}).Header().Get("ETag"))
+	assert.Equal(t, "Accept", first.Header().Get("Vary"))
+	assert.NotEqual(t, json, xml)
+	assert.Equal(t, 304, serveConditional(mw, "GET", map[string]string{"Accept": "application/xml", "If-None-Match": xml}).Code)
+	assert.Equal(t, 200, serveConditional(mw, "GET", map[string]string{"Accept": "application/json", "If-None-Match": xml}).Code)
+	assert.Equal(t, 204, serveConditional(mw, "PUT", map[string]string{"Accept": "application/json", "If-Match": xml}).Code)
+	assert.Equal(t, 412, serveConditional(mw, "PUT", map[string]string{"If-Match": `"` + strings.Repeat("0", 32) + `-1a2b3c4d"`}).Code)
+}
@@ -2,7 +2,9 @@ package middleware
 
 import (
 	"context"
+	"net/http"
 
+	"github.com/helderfarias/go-api-kit/apierror"
 	"github.com/helderfarias/go-api-kit/constants"
 	"github.com/helderfarias/go-api-kit/db"
 	"github.com/helderfarias/go-api-kit/endpoint"
@@ -23,7 +25,20 @@ func Database(dbfactory db.ConnectionFactory, key constants.DatabaseContextValue
 	}
 }
 
-func DatabaseWithTx(dbfactory db.ConnectionFactory, key constants.DatabaseContextValue) endpoint.Middleware {
+// DatabaseWithTxOptions transaction configurations
+type DatabaseWithTxOptions struct {
+	// RollbackOnErrorResponse also rolls back when the endpoint returns a response with status >= 400
+	RollbackOnErrorResponse bool
+}
+
+// DatabaseWithTx runs the endpoint inside a transaction, committed when the endpoint succeeds and
+// rolled back when it returns an error (e.g. *apierror.Error), see DatabaseWithTxOptions
+func DatabaseWithTx(dbfactory db.ConnectionFactory, key constants.DatabaseContextValue, options ...DatabaseWithTxOptions) endpoint.Middleware {
+	opt := DatabaseWithTxOptions{}
+	if len(options) >= 1 {
+		opt = options[0]
+	}
+
 	return func(next endpoint.Endpoint) endpoint.Endpoint {
 		return func(parent context.Context, request interface{}) (response endpoint.EndpointResponse, err error) {
 			tx, err := dbfactory.NewConnectionWithTransaction()
@@ -44,15 +59,16 @@ func DatabaseWithTx(dbfactory db.ConnectionFactory, key constants.DatabaseContex
 			}()
 
 			resp, err := next(ctx, request)
-			if err == nil {
+			failed := err != nil || (opt.RollbackOnErrorResponse && resp != nil && resp.Code() >= http.StatusBadRequest)
+			if !failed {
 				if err := tx.Commit(); err != nil {
 					logrus.Error(err)
-					return nil, err
+					return nil, apierror.Internal(err)
 				}
 			} else {
 				if err := tx.Rollback(); err != nil {
 					logrus.Error(err)
-					return nil, err
+					return nil, apierror.Internal(err)
 				}
 			}
 
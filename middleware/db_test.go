@@ -5,9 +5,37 @@ import (
 	"testing"
 
 	"github.com/helderfarias/go-api-kit/endpoint"
+	wrapper "github.com/helderfarias/sqlx-wrapper/db"
 	"github.com/stretchr/testify/assert"
 )
 
+type txMock struct {
+	wrapper.UnitOfWork
+	calls []string
+}
+
+func (tx *txMock) Commit() error {
+	tx.calls = append(tx.calls, "commit")
+	return nil
+}
+
+func (tx *txMock) Rollback() error {
+	tx.calls = append(tx.calls, "rollback")
+	return nil
+}
+
+type factoryMock struct {
+	tx *txMock
+}
+
+func (f *factoryMock) NewConnection() wrapper.UnitOfWork { return f.tx }
+
+func (f *factoryMock) NewConnectionWithTransaction() (wrapper.UnitOfWork, error) { return f.tx, nil }
+
+func (f *factoryMock) Delegate() interface{} { return nil }
+
+func (f *factoryMock) Close() error { return nil }
+
 func TestCreateMiddleware(t *testing.T) {
 	s := func(next endpoint.Endpoint) endpoint.Endpoint {
 		return func(parent context.Context, request interface{}) (response endpoint.EndpointResponse, err error) {
@@ -25,3 +53,15 @@ func TestCreateDatatabaseMiddleware(t *testing.T) {
 func TestCreateDatatabaseTxMiddleware(t *testing.T) {
 	assert.NotNil(t, DatabaseWithTx(nil, ""))
 }
+
+func TestDatabaseWithTxRollbackOnErrorResponseIsOptIn(t *testing.T) {
+	notFound := func(ctx context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+		return endpoint.Response(404, nil), nil
+	}
+
+	factory := &factoryMock{tx: &txMock{}}
+	DatabaseWithTx(factory, "db")(notFound)(context.Background(), nil)
+	DatabaseWithTx(factory, "db", DatabaseWithTxOptions{RollbackOnErrorResponse: true})(notFound)(context.Background(), nil)
+
+	assert.Equal(t, []string{"commit", "rollback"}, factory.tx.calls)
+}
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/helderfarias/go-api-kit/auth"
+	"github.com/helderfarias/go-api-kit/endpoint"
+	"github.com/sirupsen/logrus"
+)
+
+// JWTOptions authentication configurations
+type JWTOptions struct {
+	Verify         auth.VerifyOptions
+	TokenExtractor func(ctx context.Context, request interface{}) string
+}
+
+// DefaultTokenExtractor token stored in the context by the transport
+var DefaultTokenExtractor = func(ctx context.Context, request interface{}) string {
+	return strings.TrimSpace(strings.TrimPrefix(auth.TokenFromContext(ctx), "Bearer "))
+}
+
+// JWT verifies the bearer token with the key set, stores the claims in the context
+// (see auth.ClaimsFromContext) and responds 401 when the token is missing or invalid
+func JWT(keys auth.KeySet, options ...JWTOptions) endpoint.Middleware {
+	opt := JWTOptions{TokenExtractor: DefaultTokenExtractor}
+	if len(options) >= 1 {
+		opt = options[0]
+		if opt.TokenExtractor == nil {
+			opt.TokenExtractor = DefaultTokenExtractor
+		}
+	}
+
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(parent context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+			token := opt.TokenExtractor(parent, request)
+			if token == "" {
+				return endpoint.Response(http.StatusUnauthorized, map[string]string{"message": "token is missing"}), nil
+			}
+
+			claims, err := auth.Verify(token, keys, opt.Verify)
+			if err != nil {
+				logrus.WithField("jwt.invalid", err.Error()).Debug("JWT")
+				return endpoint.Response(http.StatusUnauthorized, map[string]string{"message": err.Error()}), nil
+			}
+
+			if parent == nil {
+				parent = context.Background()
+			}
+
+			return next(auth.ContextWithClaims(parent, claims), request)
+		}
+	}
+}
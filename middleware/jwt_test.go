@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"testing"
+
+	"github.com/helderfarias/go-api-kit/auth"
+	"github.com/helderfarias/go-api-kit/endpoint"
+	"github.com/stretchr/testify/assert"
+)
+
+func hs256(payload, secret string) string {
+	enc := base64.RawURLEncoding
+	signed := enc.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`)) + "." + enc.EncodeToString([]byte(payload))
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(signed))
+	return signed + "." + enc.EncodeToString(mac.Sum(nil))
+}
+
+func TestJWTStoreClaimsInContext(t *testing.T) {
+	ctx := auth.ContextWithToken(context.Background(), "Bearer "+hs256(`{"sub":"user"}`, "secret"))
+
+	mw := JWT(auth.StaticKey([]byte("secret")))(func(ctx context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+		claims, _ := auth.ClaimsFromContext(ctx)
+		return endpoint.Response(200, claims.Subject), nil
+	})
+
+	resp, err := mw(ctx, "request")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "user", resp.Data())
+}
+
+func TestJWTUnauthorized(t *testing.T) {
+	mw := JWT(auth.StaticKey([]byte("secret")))(func(ctx context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+		return endpoint.Response(200, "not return"), nil
+	})
+
+	missing, _ := mw(nil, "request")
+	invalid, _ := mw(auth.ContextWithToken(context.Background(), hs256(`{"sub":"user"}`, "other")), "request")
+
+	assert.Equal(t, 401, missing.Code())
+	assert.Equal(t, 401, invalid.Code())
+}
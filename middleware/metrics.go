@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/helderfarias/go-api-kit/apierror"
+	"github.com/helderfarias/go-api-kit/endpoint"
+	"github.com/helderfarias/go-api-kit/metrics"
+)
+
+// MetricsOptions metrics configurations
+type MetricsOptions struct {
+	// Registry defaults to metrics.Default
+	Registry *metrics.Registry
+	// Buckets latency buckets in seconds, defaults to metrics.DefaultBuckets
+	Buckets []float64
+}
+
+// Metrics counts the requests of the endpoint and records their latency, both labeled by the
+// endpoint name and the response code (errors use the apierror status, 500 otherwise)
+func Metrics(name string, options ...MetricsOptions) endpoint.Middleware {
+	opt := MetricsOptions{Registry: metrics.Default}
+	if len(options) >= 1 {
+		opt = options[0]
+		if opt.Registry == nil {
+			opt.Registry = metrics.Default
+		}
+	}
+
+	requests := opt.Registry.Counter("endpoint_requests_total", "Endpoint requests by response code.", "endpoint", "code")
+	latency := opt.Registry.Histogram("endpoint_request_duration_seconds", "Endpoint latency by response code.", opt.Buckets, "endpoint", "code")
+
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(parent context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+			start := time.Now()
+
+			resp, err := next(parent, request)
+
+			code := strconv.Itoa(statusOf(resp, err))
+			latency.Observe(time.Since(start).Seconds(), name, code)
+			requests.Inc(name, code)
+
+			return resp, err
+		}
+	}
+}
+
+func statusOf(resp endpoint.EndpointResponse, err error) int {
+	if err != nil {
+		return apierror.From(err).StatusCode()
+	}
+
+	if resp == nil {
+		return 200
+	}
+
+	return resp.Code()
+}
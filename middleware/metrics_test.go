@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/helderfarias/go-api-kit/apierror"
+	"github.com/helderfarias/go-api-kit/endpoint"
+	"github.com/helderfarias/go-api-kit/metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsByEndpointAndCode(t *testing.T) {
+	registry := metrics.NewRegistry()
+	mw := Metrics("orders.get", MetricsOptions{Registry: registry})
+
+	mw(func(ctx context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+		return endpoint.Response(200, "ok"), nil
+	})(nil, nil)
+	mw(func(ctx context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+		return nil, apierror.NotFound("order not found")
+	})(nil, nil)
+	mw(func(ctx context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+		return nil, errors.New("boom")
+	})(nil, nil)
+
+	buf := &bytes.Buffer{}
+	registry.WriteText(buf)
+
+	assert.Contains(t, buf.String(), `endpoint_requests_total{endpoint="orders.get",code="200"} 1`)
+	assert.Contains(t, buf.String(), `endpoint_requests_total{endpoint="orders.get",code="404"} 1`)
+	assert.Contains(t, buf.String(), `endpoint_requests_total{endpoint="orders.get",code="500"} 1`)
+	assert.Contains(t, buf.String(), `endpoint_request_duration_seconds_count{endpoint="orders.get",code="200"} 1`)
+}
@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/helderfarias/go-api-kit/endpoint"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrRateLimited returned in the response data when the limiter rejects a request
+var ErrRateLimited = errors.New("rate limit exceeded")
+
+// RateLimitOptions rate limit configurations
+type RateLimitOptions struct {
+	// Rate tokens added per second
+	Rate float64
+	// Burst bucket capacity
+	Burst        int
+	KeyExtractor func(ctx context.Context, request interface{}) string
+	OnListener   func(event string, key string)
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+type rateLimiter struct {
+	mu      sync.Mutex
+	rate    float64
+	burst   float64
+	buckets map[string]*tokenBucket
+	sweep   time.Time
+}
+
+// DefaultKeyExtractor one bucket shared by all requests
+var DefaultKeyExtractor = func(ctx context.Context, request interface{}) string {
+	return ""
+}
+
+// RateLimit in-process token bucket limiter, one bucket per key returned by the KeyExtractor.
+// Requests without tokens are rejected with 429 and Retry-After.
+func RateLimit(name string, options ...RateLimitOptions) endpoint.Middleware {
+	opt := RateLimitOptions{}
+	if len(options) >= 1 {
+		opt = options[0]
+	}
+
+	if opt.Rate == 0 {
+		opt.Rate = 10
+	}
+	if opt.Burst == 0 {
+		opt.Burst = int(math.Ceil(opt.Rate))
+	}
+	if opt.KeyExtractor == nil {
+		opt.KeyExtractor = DefaultKeyExtractor
+	}
+	if opt.OnListener == nil {
+		opt.OnListener = DefaultListener
+	}
+
+	limiter := &rateLimiter{
+		rate:    opt.Rate,
+		burst:   float64(opt.Burst),
+		buckets: map[string]*tokenBucket{},
+		sweep:   time.Now(),
+	}
+
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(parent context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+			key := name + ":" + opt.KeyExtractor(parent, request)
+
+			allowed, wait := limiter.allow(key, time.Now())
+			if !allowed {
+				logrus.WithField("ratelimit.rejected", key).Debug("RateLimit")
+				opt.OnListener("rejected", key)
+				resp := endpoint.Response(http.StatusTooManyRequests, map[string]string{"message": ErrRateLimited.Error()})
+				return endpoint.WithHeader(resp, "Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds())))), nil
+			}
+
+			return next(parent, request)
+		}
+	}
+}
+
+// allow takes a token, otherwise returns the time until the next one
+func (r *rateLimiter) allow(key string, now time.Time) (bool, time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cleanup(now)
+
+	bucket, ok := r.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: r.burst, last: now}
+		r.buckets[key] = bucket
+	}
+
+	elapsed := now.Sub(bucket.last).Seconds()
+	bucket.tokens = math.Min(r.burst, bucket.tokens+elapsed*r.rate)
+	bucket.last = now
+
+	if bucket.tokens < 1 {
+		return false, time.Duration((1 - bucket.tokens) / r.rate * float64(time.Second))
+	}
+
+	bucket.tokens--
+	return true, 0
+}
+
+// cleanup drops buckets that are already full again, they behave like new ones
+func (r *rateLimiter) cleanup(now time.Time) {
+	full := time.Duration(r.burst / r.rate * float64(time.Second))
+	if now.Sub(r.sweep) < full || now.Sub(r.sweep) < time.Minute {
+		return
+	}
+
+	for key, bucket := range r.buckets {
+		if now.Sub(bucket.last) >= full {
+			delete(r.buckets, key)
+		}
+	}
+
+	r.sweep = now
+}
@@ -0,0 +1,419 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/helderfarias/go-api-kit/cache"
+	"github.com/helderfarias/go-api-kit/endpoint"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+const (
+	// SlidingWindow counts the requests made in the last period
+	SlidingWindow = "sliding-window"
+	// GCRA generic cell rate algorithm, spreads the quota evenly allowing bursts
+	GCRA = "gcra"
+)
+
+// Quota requests allowed per period, Burst is used only by GCRA and defaults to Limit
+type Quota struct {
+	Limit  int
+	Period time.Duration
+	Burst  int
+}
+
+// RateLimitInfo quota state after the request
+type RateLimitInfo struct {
+	Limit     int
+	Remaining int
+	Reset     time.Duration
+}
+
+// RateLimitedResponse response carrying the quota metadata
+type RateLimitedResponse interface {
+	endpoint.EndpointResponse
+
+	RateLimit() RateLimitInfo
+}
+
+// DistributedRateLimitOptions distributed rate limit configurations
+type DistributedRateLimitOptions struct {
+	Algorithm string
+	// Quota applied to keys without an entry in Quotas
+	Quota Quota
+	// Quotas per key (API key, tenant, IP...), the "default" entry replaces Quota
+	Quotas       map[string]Quota
+	KeyExtractor func(ctx context.Context, request interface{}) string
+	OnListener   func(event string, key string)
+}
+
+type rateLimitedResponse struct {
+	endpoint.EndpointResponse
+	info RateLimitInfo
+}
+
+type quotaStore interface {
+	take(key, algorithm string, quota Quota, now time.Time) (bool, RateLimitInfo, error)
+}
+
+type scriptQuotaStore struct {
+	runner cache.ScriptRunner
+}
+
+type localQuotaStore struct {
+	mu      sync.Mutex
+	windows map[string][]time.Time
+	tats    map[string]time.Time
+	// expires when the key is back to a full quota, used by the cleanup
+	expires map[string]time.Time
+	sweep   time.Time
+}
+
+const slidingWindowScript = `
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+redis.call('ZREMRANGEBYSCORE', key, 0, now - window)
+local count = redis.call('ZCARD', key)
+local allowed = 0
+if count < limit then
+	redis.call('ZADD', key, now, ARGV[4])
+	count = count + 1
+	allowed = 1
+end
+redis.call('PEXPIRE', key, window)
+local reset = window
+local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+if oldest[2] then
+	reset = tonumber(oldest[2]) + window - now
+end
+return {allowed, limit - count, reset}
+`
+
+const gcraScript = `
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local emission = tonumber(ARGV[2])
+local tolerance = emission * tonumber(ARGV[3])
+local tat = tonumber(redis.call('GET', key)) or now
+if tat < now then
+	tat = now
+end
+local newtat = tat + emission
+local allowAt = newtat - tolerance
+if now < allowAt then
+	return {0, 0, math.ceil(allowAt - now)}
+end
+redis.call('SET', key, newtat, 'PX', math.ceil(newtat - now))
+return {1, math.floor((tolerance - (newtat - now)) / emission), math.ceil(newtat - now)}
+`
+
+// DistributedRateLimit rate limit shared by all replicas. The algorithms run as Lua scripts in
+// Redis so the check and the update are atomic, falling back to an in-process store when the
+// cache server can't run scripts (memory cache). Responses carry the quota metadata, see
+// RateLimitedResponse, and rejected requests get 429.
+func DistributedRateLimit(cacheServer cache.CacheServer, name string, options ...DistributedRateLimitOptions) endpoint.Middleware {
+	opt := DistributedRateLimitOptions{}
+	if len(options) >= 1 {
+		opt = options[0]
+	}
+
+	if opt.Algorithm == "" {
+		opt.Algorithm = SlidingWindow
+	}
+	if def, ok := opt.Quotas["default"]; ok {
+		opt.Quota = def
+	}
+	opt.Quota = opt.Quota.withDefaults()
+
+	quotas := map[string]Quota{}
+	for id, q := range opt.Quotas {
+		quotas[id] = q.withDefaults()
+	}
+	opt.Quotas = quotas
+
+	if opt.KeyExtractor == nil {
+		opt.KeyExtractor = DefaultKeyExtractor
+	}
+	if opt.OnListener == nil {
+		opt.OnListener = DefaultListener
+	}
+
+	var store quotaStore
+	if runner, ok := cacheServer.(cache.ScriptRunner); ok {
+		store = &scriptQuotaStore{runner: runner}
+	} else {
+		logrus.Warnf("Cache server can't run scripts, rate limit %q working in-process", name)
+		store = newLocalQuotaStore()
+	}
+
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(parent context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+			id := opt.KeyExtractor(parent, request)
+			key := fmt.Sprintf("ratelimit:%v:%v", name, id)
+
+			quota := opt.Quota
+			if q, ok := opt.Quotas[id]; ok {
+				quota = q
+			} else if q, ok := opt.Quotas[strings.ToLower(id)]; ok {
+				quota = q
+			}
+
+			allowed, info, err := store.take(key, opt.Algorithm, quota, time.Now())
+			if err != nil {
+				logrus.Error(err)
+				return next(parent, request)
+			}
+
+			if !allowed {
+				logrus.WithField("ratelimit.rejected", key).Debug("DistributedRateLimit")
+				opt.OnListener("rejected", key)
+				resp := endpoint.Response(http.StatusTooManyRequests, map[string]string{"message": ErrRateLimited.Error()})
+				return &rateLimitedResponse{EndpointResponse: resp, info: info}, nil
+			}
+
+			resp, err := next(parent, request)
+			if resp == nil {
+				return resp, err
+			}
+
+			return &rateLimitedResponse{EndpointResponse: resp, info: info}, err
+		}
+	}
+}
+
+// QuotasFromConfig reads the quotas of the limiter from viper key "ratelimit_<name>_quotas",
+// a map of key to "limit/period[/burst]", e.g. {default: 100/1m, tenant-a: 1000/1m/50}.
+// Viper keys are case insensitive, so map keys are lower case.
+func QuotasFromConfig(name string) (map[string]Quota, error) {
+	quotas := map[string]Quota{}
+
+	for key, value := range viper.GetStringMapString(fmt.Sprintf("ratelimit_%v_quotas", name)) {
+		quota, err := ParseQuota(value)
+		if err != nil {
+			return nil, fmt.Errorf("ratelimit %v, quota %v: %v", name, key, err)
+		}
+
+		quotas[key] = quota
+	}
+
+	return quotas, nil
+}
+
+// ParseQuota parses "limit/period[/burst]", e.g. "100/1m" or "10/1s/20"
+func ParseQuota(value string) (Quota, error) {
+	parts := strings.Split(strings.TrimSpace(value), "/")
+	if len(parts) < 2 || len(parts) > 3 {
+		return Quota{}, fmt.Errorf("invalid quota %q, expected limit/period[/burst]", value)
+	}
+
+	limit, err := strconv.Atoi(parts[0])
+	if err != nil || limit <= 0 {
+		return Quota{}, fmt.Errorf("invalid quota limit %q", parts[0])
+	}
+
+	period, err := time.ParseDuration(parts[1])
+	if err != nil || period <= 0 {
+		return Quota{}, fmt.Errorf("invalid quota period %q", parts[1])
+	}
+
+	quota := Quota{Limit: limit, Period: period}
+
+	if len(parts) == 3 {
+		burst, err := strconv.Atoi(parts[2])
+		if err != nil || burst <= 0 {
+			return Quota{}, fmt.Errorf("invalid quota burst %q", parts[2])
+		}
+		quota.Burst = burst
+	}
+
+	return quota, nil
+}
+
+func (r *rateLimitedResponse) RateLimit() RateLimitInfo {
+	return r.info
+}
+
+// Headers the headers of the wrapped response plus X-RateLimit-Limit, X-RateLimit-Remaining,
+// X-RateLimit-Reset (seconds) and Retry-After for rejected requests
+func (r *rateLimitedResponse) Headers() http.Header {
+	headers := http.Header{}
+	for k, v := range endpoint.HeadersOf(r.EndpointResponse) {
+		headers[k] = v
+	}
+
+	reset := strconv.Itoa(int(math.Ceil(r.info.Reset.Seconds())))
+
+	headers.Set("X-RateLimit-Limit", strconv.Itoa(r.info.Limit))
+	headers.Set("X-RateLimit-Remaining", strconv.Itoa(r.info.Remaining))
+	headers.Set("X-RateLimit-Reset", reset)
+	if r.Code() == http.StatusTooManyRequests {
+		headers.Set("Retry-After", reset)
+	}
+
+	return headers
+}
+
+func (r *rateLimitedResponse) Cookies() []*http.Cookie {
+	return endpoint.CookiesOf(r.EndpointResponse)
+}
+
+func (r *rateLimitedResponse) Stream() endpoint.StreamFunc {
+	stream, _ := endpoint.StreamOf(r.EndpointResponse)
+	return stream
+}
+
+// withDefaults 100 requests per minute for the unset (or negative) fields
+func (q Quota) withDefaults() Quota {
+	if q.Limit <= 0 {
+		q.Limit = 100
+	}
+	if q.Period <= 0 {
+		q.Period = time.Minute
+	}
+	return q
+}
+
+func (q Quota) burst() int {
+	if q.Burst > 0 {
+		return q.Burst
+	}
+	return q.Limit
+}
+
+func (q Quota) emission() time.Duration {
+	return q.Period / time.Duration(q.Limit)
+}
+
+func (s *scriptQuotaStore) take(key, algorithm string, quota Quota, now time.Time) (bool, RateLimitInfo, error) {
+	nowMs := now.UnixNano() / int64(time.Millisecond)
+
+	var result interface{}
+	var err error
+
+	switch algorithm {
+	case GCRA:
+		emission := float64(quota.emission()) / float64(time.Millisecond)
+		result, err = s.runner.Eval(gcraScript, []string{key}, nowMs, emission, quota.burst())
+	case SlidingWindow:
+		nonce := make([]byte, 8)
+		if _, err := rand.Read(nonce); err != nil {
+			return false, RateLimitInfo{}, err
+		}
+		member := fmt.Sprintf("%v-%v", nowMs, hex.EncodeToString(nonce))
+		result, err = s.runner.Eval(slidingWindowScript, []string{key}, nowMs, quota.Period.Nanoseconds()/int64(time.Millisecond), quota.Limit, member)
+	default:
+		return false, RateLimitInfo{}, fmt.Errorf("unknown rate limit algorithm %q", algorithm)
+	}
+
+	if err != nil {
+		return false, RateLimitInfo{}, err
+	}
+
+	values, ok := result.([]interface{})
+	if !ok || len(values) != 3 {
+		return false, RateLimitInfo{}, errors.New("unexpected rate limit script result")
+	}
+
+	allowed, _ := values[0].(int64)
+	remaining, _ := values[1].(int64)
+	reset, _ := values[2].(int64)
+
+	limit := quota.Limit
+	if algorithm == GCRA {
+		limit = quota.burst()
+	}
+
+	info := RateLimitInfo{
+		Limit:     limit,
+		Remaining: int(remaining),
+		Reset:     time.Duration(reset) * time.Millisecond,
+	}
+
+	return allowed == 1, info, nil
+}
+
+func newLocalQuotaStore() *localQuotaStore {
+	return &localQuotaStore{
+		windows: map[string][]time.Time{},
+		tats:    map[string]time.Time{},
+		expires: map[string]time.Time{},
+		sweep:   time.Now(),
+	}
+}
+
+func (s *localQuotaStore) take(key, algorithm string, quota Quota, now time.Time) (bool, RateLimitInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cleanup(now)
+
+	switch algorithm {
+	case GCRA:
+		emission := quota.emission()
+		tolerance := emission * time.Duration(quota.burst())
+
+		tat := s.tats[key]
+		if tat.Before(now) {
+			tat = now
+		}
+
+		newTat := tat.Add(emission)
+		allowAt := newTat.Add(-tolerance)
+		if now.Before(allowAt) {
+			return false, RateLimitInfo{Limit: quota.burst(), Reset: allowAt.Sub(now)}, nil
+		}
+
+		s.tats[key] = newTat
+		s.expires[key] = newTat
+		remaining := int(math.Floor(float64(tolerance-newTat.Sub(now)) / float64(emission)))
+		return true, RateLimitInfo{Limit: quota.burst(), Remaining: remaining, Reset: newTat.Sub(now)}, nil
+	case SlidingWindow:
+		window := []time.Time{}
+		for _, t := range s.windows[key] {
+			if now.Sub(t) < quota.Period {
+				window = append(window, t)
+			}
+		}
+
+		allowed := len(window) < quota.Limit
+		if allowed {
+			window = append(window, now)
+		}
+		s.windows[key] = window
+		s.expires[key] = window[len(window)-1].Add(quota.Period)
+
+		return allowed, RateLimitInfo{Limit: quota.Limit, Remaining: quota.Limit - len(window), Reset: quota.Period - now.Sub(window[0])}, nil
+	}
+
+	return false, RateLimitInfo{}, fmt.Errorf("unknown rate limit algorithm %q", algorithm)
+}
+
+// cleanup drops keys that are back to a full quota, they behave like new ones
+func (s *localQuotaStore) cleanup(now time.Time) {
+	if now.Sub(s.sweep) < time.Minute {
+		return
+	}
+
+	for key, expires := range s.expires {
+		if !now.Before(expires) {
+			delete(s.windows, key)
+			delete(s.tats, key)
+			delete(s.expires, key)
+		}
+	}
+
+	s.sweep = now
+}
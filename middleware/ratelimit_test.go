@@ -0,0 +1,145 @@
+package middleware
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/helderfarias/go-api-kit/endpoint"
+	"github.com/helderfarias/go-api-kit/httptransport"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimitByKey(t *testing.T) {
+	mw := RateLimit("addresses", RateLimitOptions{
+		Rate:  1,
+		Burst: 1,
+		KeyExtractor: func(ctx context.Context, request interface{}) string {
+			return request.(string)
+		},
+	})(func(ctx context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+		return endpoint.Response(200, "ok"), nil
+	})
+
+	first, _ := mw(nil, "tenant-a")
+	second, _ := mw(nil, "tenant-a")
+	other, _ := mw(nil, "tenant-b")
+
+	assert.Equal(t, 200, first.Code())
+	assert.Equal(t, 429, second.Code())
+	assert.Equal(t, "1", endpoint.HeadersOf(second).Get("Retry-After"))
+	assert.Equal(t, 200, other.Code())
+}
+
+type scriptRunnerMock struct {
+	cacheServerMock
+}
+
+func (s *scriptRunnerMock) Eval(script string, keys []string, args ...interface{}) (interface{}, error) {
+	call := s.Called(keys)
+	return call.Get(0), call.Error(1)
+}
+
+func TestDistributedRateLimitRunScript(t *testing.T) {
+	cacheMock := &scriptRunnerMock{}
+
+	cacheMock.On("Eval", []string{"ratelimit:addresses:tenant-a"}).Return([]interface{}{int64(1), int64(9), int64(60000)}, nil)
+
+	mw := DistributedRateLimit(cacheMock, "addresses", DistributedRateLimitOptions{
+		Quota: Quota{Limit: 10, Period: time.Minute},
+		KeyExtractor: func(ctx context.Context, request interface{}) string {
+			return "tenant-a"
+		},
+	})(func(ctx context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+		return endpoint.Response(200, "ok"), nil
+	})
+
+	resp, err := mw(nil, "request")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "ok", resp.Data())
+	assert.Equal(t, RateLimitInfo{Limit: 10, Remaining: 9, Reset: time.Minute}, resp.(RateLimitedResponse).RateLimit())
+	cacheMock.AssertExpectations(t)
+}
+
+func TestDistributedRateLimitInProcessGCRA(t *testing.T) {
+	mw := DistributedRateLimit(&cacheServerMock{}, "addresses", DistributedRateLimitOptions{
+		Algorithm: GCRA,
+		Quota:     Quota{Limit: 2, Period: time.Minute},
+	})(func(ctx context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+		return endpoint.Response(200, "ok"), nil
+	})
+
+	first, _ := mw(nil, "request")
+	second, _ := mw(nil, "request")
+	third, _ := mw(nil, "request")
+
+	assert.Equal(t, 200, first.Code())
+	assert.Equal(t, 1, first.(RateLimitedResponse).RateLimit().Remaining)
+	assert.Equal(t, 200, second.Code())
+	assert.Equal(t, 429, third.Code())
+}
+
+func TestLocalQuotaStoreEvictsFullKeys(t *testing.T) {
+	store := newLocalQuotaStore()
+	quota := Quota{Limit: 2, Period: time.Second}
+	now := time.Now()
+
+	store.take("a", SlidingWindow, quota, now)
+	store.take("b", GCRA, quota, now)
+	store.take("c", GCRA, quota, now.Add(2*time.Minute))
+
+	assert.Len(t, store.windows, 0)
+	assert.Len(t, store.tats, 1)
+	assert.Len(t, store.expires, 1)
+}
+
+func TestDistributedRateLimitZeroLimitQuotaUsesDefaults(t *testing.T) {
+	for _, algorithm := range []string{SlidingWindow, GCRA} {
+		mw := DistributedRateLimit(&cacheServerMock{}, "addresses", DistributedRateLimitOptions{
+			Algorithm: algorithm,
+			Quotas:    map[string]Quota{"tenant-a": {Limit: 0}},
+			KeyExtractor: func(ctx context.Context, request interface{}) string {
+				return "tenant-a"
+			},
+		})(func(ctx context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+			return endpoint.Response(200, "ok"), nil
+		})
+
+		resp, err := mw(context.Background(), "request")
+
+		assert.Nil(t, err, algorithm)
+		assert.Equal(t, 200, resp.Code(), algorithm)
+		assert.Equal(t, 100, resp.(RateLimitedResponse).RateLimit().Limit, algorithm)
+	}
+}
+
+func TestDistributedRateLimitKeepsTheStream(t *testing.T) {
+	mw := DistributedRateLimit(&cacheServerMock{}, "events")(func(ctx context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+		resp := endpoint.Stream(200, func(ctx context.Context, lastEventID string, send func(endpoint.Event) error) error {
+			return send(endpoint.Event{Data: map[string]int{"id": 1}})
+		})
+		return endpoint.WithHeader(resp, "X-Stream", "true"), nil
+	})
+
+	handler := httptransport.NewServer(mw, httptransport.NopRequestDecoder, httptransport.EncodeNDJSONResponse)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/events", nil))
+
+	assert.Equal(t, 200, rec.Code)
+	assert.Equal(t, "true", rec.Header().Get("X-Stream"))
+	assert.Equal(t, "100", rec.Header().Get("X-RateLimit-Limit"))
+	assert.Equal(t, "{\"id\":1}\n", rec.Body.String())
+}
+
+func TestQuotasFromConfig(t *testing.T) {
+	viper.Set("ratelimit_addresses_quotas", map[string]interface{}{"default": "100/1m", "tenant-a": "10/1s/20"})
+
+	quotas, err := QuotasFromConfig("addresses")
+
+	assert.Nil(t, err)
+	assert.Equal(t, Quota{Limit: 100, Period: time.Minute}, quotas["default"])
+	assert.Equal(t, Quota{Limit: 10, Period: time.Second, Burst: 20}, quotas["tenant-a"])
+}
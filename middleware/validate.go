@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/helderfarias/go-api-kit/endpoint"
+	"github.com/helderfarias/go-api-kit/validation"
+	"github.com/sirupsen/logrus"
+)
+
+// ValidateOptions validation configurations
+type ValidateOptions struct {
+	Validator *validation.Validator
+}
+
+// ValidationResult body of the 422 response
+type ValidationResult struct {
+	Message string            `json:"message"`
+	Errors  validation.Errors `json:"errors"`
+}
+
+// Validate checks the request struct tags before calling the endpoint and
+// responds 422 with the list of field errors when any rule fails
+func Validate(options ...ValidateOptions) endpoint.Middleware {
+	opt := ValidateOptions{Validator: validation.Default}
+	if len(options) >= 1 {
+		opt = options[0]
+		if opt.Validator == nil {
+			opt.Validator = validation.Default
+		}
+	}
+
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(parent context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+			err := opt.Validator.Validate(request)
+			if err == nil {
+				return next(parent, request)
+			}
+
+			errs, ok := err.(validation.Errors)
+			if !ok {
+				return nil, err
+			}
+
+			logrus.WithField("validation.errors", len(errs)).Debug("Validate")
+			return endpoint.Response(http.StatusUnprocessableEntity, ValidationResult{Message: "validation failed", Errors: errs}), nil
+		}
+	}
+}
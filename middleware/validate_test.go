@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+
+	"github.com/helderfarias/go-api-kit/endpoint"
+	"github.com/stretchr/testify/assert"
+)
+
+type addressRequest struct {
+	Street string `json:"street" validate:"required"`
+}
+
+func TestValidateRequestWithFieldErrors(t *testing.T) {
+	mw := Validate()(func(ctx context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+		return endpoint.Response(200, "not return"), nil
+	})
+
+	resp, err := mw(nil, &addressRequest{})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 422, resp.Code())
+	assert.Equal(t, "street", resp.Data().(ValidationResult).Errors[0].Field)
+}
+
+func TestValidateRequestValid(t *testing.T) {
+	mw := Validate()(func(ctx context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+		return endpoint.Response(200, "ok"), nil
+	})
+
+	resp, err := mw(nil, &addressRequest{Street: "Main"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "ok", resp.Data())
+}
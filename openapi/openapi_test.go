@@ -0,0 +1,183 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/helderfarias/go-api-kit/apierror"
+	"github.com/helderfarias/go-api-kit/validation"
+	"github.com/stretchr/testify/assert"
+)
+
+type address struct {
+	ID      int64     `json:"id"`
+	Street  string    `json:"street" validate:"required,max=120"`
+	Kind    string    `json:"kind,omitempty" validate:"oneof=home work"`
+	Tags    []string  `json:"tags,omitempty"`
+	Created time.Time `json:"created"`
+	Owner   *owner    `json:"owner,omitempty"`
+}
+
+type owner struct {
+	Name string `json:"name"`
+}
+
+type listAddresses struct {
+	UserID int64  `path:"id"`
+	Page   int    `query:"page" default:"1" validate:"min=1"`
+	Tenant string `header:"X-Tenant" validate:"required"`
+}
+
+type createAddress struct {
+	UserID int64  `path:"id"`
+	Street string `json:"street" validate:"required,max=10"`
+	Number int    `json:"number" validate:"min=1"`
+}
+
+func newTestRegistry() *Registry {
+	return NewRegistry(Info{Title: "Addresses", Version: "1.0"}).
+		SecurityScheme("bearer", SecurityScheme{Type: "http", Scheme: "bearer", BearerFormat: "JWT"}).
+		Add(EndpointSpec{
+			Method:    "get",
+			Path:      "/users/{id}/addresses",
+			ID:        "listAddresses",
+			Tags:      []string{"addresses"},
+			Request:   listAddresses{},
+			Responses: map[int]ResponseSpec{200: {Body: Paged(address{})}, 404: {Body: apierror.Problem{}}},
+			Security:  []string{"bearer"},
+		}).
+		Add(EndpointSpec{
+			Method:    "POST",
+			Path:      "/users/{id}/addresses",
+			Request:   &createAddress{},
+			Responses: map[int]ResponseSpec{201: {Description: "created", Body: address{}}},
+		}).
+		Add(EndpointSpec{Method: "GET", Path: "/files/{path...}"})
+}
+
+func TestDocumentFromRegisteredEndpoints(t *testing.T) {
+	doc := newTestRegistry().Document()
+
+	list := (*doc.Paths["/users/{id}/addresses"])["get"]
+	assert.Equal(t, "listAddresses", list.OperationID)
+	assert.Equal(t, []map[string][]string{{"bearer": {}}}, list.Security)
+	assert.Equal(t, []*Parameter{
+		{Name: "id", In: "path", Required: true, Schema: &Schema{Type: "integer", Format: "int64"}},
+		{Name: "page", In: "query", Schema: &Schema{Type: "integer", Format: "int64", Default: int64(1), Minimum: float(1)}},
+		{Name: "X-Tenant", In: "header", Required: true, Schema: &Schema{Type: "string"}},
+	}, list.Parameters)
+
+	paged := list.Responses["200"].Content["application/json"].Schema
+	assert.Equal(t, &Schema{Type: "array", Items: &Schema{Ref: "#/components/schemas/address"}}, paged.Properties["data"])
+	assert.Equal(t, &Schema{Ref: "#/components/schemas/Paging"}, paged.Properties["paging"])
+	assert.Equal(t, "integer", doc.Components.Schemas["Paging"].Properties["totalPages"].Type)
+	assert.Contains(t, list.Responses["404"].Content, apierror.ProblemContentType)
+
+	schema := doc.Components.Schemas["address"]
+	max := 120
+	assert.Equal(t, []string{"street"}, schema.Required)
+	assert.Equal(t, &max, schema.Properties["street"].MaxLength)
+	assert.Equal(t, []interface{}{"home", "work"}, schema.Properties["kind"].Enum)
+	assert.Equal(t, &Schema{Type: "string", Format: "date-time"}, schema.Properties["created"])
+	assert.Equal(t, &Schema{AllOf: []*Schema{{Ref: "#/components/schemas/owner"}}, Nullable: true}, schema.Properties["owner"])
+
+	create := (*doc.Paths["/users/{id}/addresses"])["post"]
+	assert.Equal(t, "created", create.Responses["201"].Description)
+	assert.Equal(t, []string{"street", "number"}, keys(create.RequestBody.Content["application/json"].Schema.Properties))
+
+	files := (*doc.Paths["/files/{path}"])["get"]
+	assert.Equal(t, "path", files.Parameters[0].Name)
+	assert.Equal(t, "OK", files.Responses["200"].Description)
+}
+
+func TestHandlerServesJSONAndYAML(t *testing.T) {
+	handler := newTestRegistry().Handler()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/openapi.json", nil))
+
+	doc := map[string]interface{}{}
+	assert.Nil(t, json.Unmarshal(rec.Body.Bytes(), &doc))
+	assert.Equal(t, "3.0.3", doc["openapi"])
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/openapi.yaml", nil))
+
+	assert.Equal(t, "application/yaml", rec.Header().Get("Content-Type"))
+	assert.True(t, strings.HasPrefix(rec.Body.String(), "openapi: 3.0.3\ninfo:\n  title: Addresses\n"))
+}
+
+func TestValidatorChecksParamsAndBody(t *testing.T) {
+	v := newTestRegistry().Validator()
+
+	r := httptest.NewRequest("GET", "/users/10/addresses?page=2", nil)
+	r.Header.Set("X-Tenant", "acme")
+	assert.Nil(t, v.Validate(r))
+
+	r = httptest.NewRequest("GET", "/users/abc/addresses?page=0", nil)
+	e, _ := apierror.As(v.Validate(r))
+	assert.Equal(t, []string{"id must be a valid integer", "page must be at least 1", "X-Tenant is required"}, messages(e.Details.(validation.Errors)))
+
+	r = httptest.NewRequest("POST", "/users/10/addresses", strings.NewReader(`{"street":"Very long street name","number":"10"}`))
+	e, _ = apierror.As(v.Validate(r))
+	assert.ElementsMatch(t, []string{"street must be at most 10", "number must be a valid integer"}, messages(e.Details.(validation.Errors)))
+
+	r = httptest.NewRequest("POST", "/users/10/addresses", strings.NewReader(`{"number":1}`))
+	rec := httptest.NewRecorder()
+	v.Middleware(http.NotFoundHandler()).ServeHTTP(rec, r)
+	assert.Equal(t, 422, rec.Code)
+	assert.Contains(t, rec.Body.String(), "street is required")
+
+	r = httptest.NewRequest("POST", "/users/10/addresses", strings.NewReader(`street=Main`))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	e, _ = apierror.As(v.Validate(r))
+	assert.Equal(t, 415, e.StatusCode())
+
+	assert.Nil(t, v.Validate(httptest.NewRequest("DELETE", "/undeclared", nil)))
+}
+
+func TestValidatorNullableRefsPatternsAndBodySize(t *testing.T) {
+	type updateOwner struct {
+		Owner *owner `json:"owner"`
+		Code  string `json:"code" validate:"regex=^[0-9]+$"`
+	}
+
+	registry := NewRegistry(Info{Title: "Owners", Version: "1.0"}).Add(EndpointSpec{Method: "PUT", Path: "/owners", Request: updateOwner{}})
+	v := registry.Validator(ValidatorOptions{MaxBodySize: 64})
+
+	assert.Nil(t, v.Validate(httptest.NewRequest("PUT", "/owners", strings.NewReader(`{"owner":null,"code":"12"}`))))
+
+	e, _ := apierror.As(v.Validate(httptest.NewRequest("PUT", "/owners", strings.NewReader(`{"owner":{"name":1},"code":"x"}`))))
+	assert.ElementsMatch(t, []string{"owner.name must be a valid string", "code must match ^[0-9]+$"}, messages(e.Details.(validation.Errors)))
+
+	e, _ = apierror.As(v.Validate(httptest.NewRequest("PUT", "/owners", strings.NewReader(`{"code":"`+strings.Repeat("1", 100)+`"}`))))
+	assert.Equal(t, 413, e.StatusCode())
+
+	type invalidPattern struct {
+		Code string `json:"code" validate:"regex=^[0-9$"`
+	}
+	invalid := NewRegistry(Info{Title: "Invalid", Version: "1.0"}).Add(EndpointSpec{Method: "PUT", Path: "/codes", Request: invalidPattern{}})
+	assert.Panics(t, func() { invalid.Validator() })
+}
+
+func keys(properties map[string]*Schema) []string {
+	names := []string{}
+	for _, name := range []string{"street", "number", "id"} {
+		if _, ok := properties[name]; ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func messages(errs validation.Errors) []string {
+	list := []string{}
+	for _, e := range errs {
+		list = append(list, e.Message)
+	}
+	return list
+}
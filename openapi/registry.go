@@ -0,0 +1,321 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/helderfarias/go-api-kit/apierror"
+	"github.com/helderfarias/go-api-kit/endpoint"
+	"gopkg.in/yaml.v2"
+)
+
+// EndpointSpec declaration of an endpoint. Request is a value of the request type, fields with
+// path, query, header or form tags (see the binding package) are parameters and the other
+// fields the JSON body; validate tags become schema constraints.
+type EndpointSpec struct {
+	Method      string
+	Path        string
+	ID          string
+	Summary     string
+	Description string
+	Tags        []string
+	Request     interface{}
+	// Responses by status, a 200 without body is declared when empty
+	Responses map[int]ResponseSpec
+	// Security names of the security schemes required
+	Security   []string
+	Deprecated bool
+}
+
+// ResponseSpec response of a status, Body is a value of the response type
+// (e.g. Paged(Address{})), apierror.Problem bodies are application/problem+json
+type ResponseSpec struct {
+	Description string
+	Body        interface{}
+}
+
+// Registry endpoints declared for the document
+type Registry struct {
+	mu       sync.RWMutex
+	info     Info
+	servers  []Server
+	specs    []EndpointSpec
+	security map[string]*SecurityScheme
+}
+
+// NewRegistry constructs an empty registry
+func NewRegistry(info Info, servers ...Server) *Registry {
+	return &Registry{info: info, servers: servers, security: map[string]*SecurityScheme{}}
+}
+
+// Paged paged response of the items, e.g. Paged(Address{}) for endpoint.Paginate([]Address...)
+func Paged(item interface{}) endpoint.EntityPaging {
+	return endpoint.EntityPaging{Data: reflect.MakeSlice(reflect.SliceOf(reflect.TypeOf(item)), 0, 0).Interface()}
+}
+
+// Add declares the endpoint
+func (r *Registry) Add(spec EndpointSpec) *Registry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	spec.Method = strings.ToUpper(spec.Method)
+	r.specs = append(r.specs, spec)
+	return r
+}
+
+// SecurityScheme declares a security scheme referenced by EndpointSpec.Security
+func (r *Registry) SecurityScheme(name string, scheme SecurityScheme) *Registry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.security[name] = &scheme
+	return r
+}
+
+// Document generates the document of the declared endpoints
+func (r *Registry) Document() *Document {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	b := newSchemaBuilder()
+
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    r.info,
+		Servers: r.servers,
+		Paths:   map[string]*PathItem{},
+	}
+
+	for _, spec := range r.specs {
+		path := specPath(spec.Path)
+
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = &PathItem{}
+			doc.Paths[path] = item
+		}
+
+		(*item)[strings.ToLower(spec.Method)] = b.operation(spec)
+	}
+
+	doc.Components.Schemas = b.schemas
+	if len(r.security) > 0 {
+		doc.Components.SecuritySchemes = r.security
+	}
+
+	return doc
+}
+
+// JSON document as JSON
+func (r *Registry) JSON() ([]byte, error) {
+	return json.MarshalIndent(r.Document(), "", "  ")
+}
+
+// YAML document as YAML
+func (r *Registry) YAML() ([]byte, error) {
+	enc, err := json.Marshal(r.Document())
+	if err != nil {
+		return nil, err
+	}
+
+	var doc yaml.MapSlice
+	if err := yaml.Unmarshal(enc, &doc); err != nil {
+		return nil, err
+	}
+
+	return yaml.Marshal(doc)
+}
+
+// Handler serves the document as JSON, or YAML when the path ends with .yaml/.yml or format=yaml
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if strings.HasSuffix(req.URL.Path, ".yaml") || strings.HasSuffix(req.URL.Path, ".yml") || req.URL.Query().Get("format") == "yaml" {
+			doc, err := r.YAML()
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/yaml")
+			w.Write(doc)
+			return
+		}
+
+		doc, err := r.JSON()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Write(doc)
+	})
+}
+
+func (b *schemaBuilder) operation(spec EndpointSpec) *Operation {
+	op := &Operation{
+		OperationID: spec.ID,
+		Summary:     spec.Summary,
+		Description: spec.Description,
+		Tags:        spec.Tags,
+		Responses:   map[string]*Response{},
+		Deprecated:  spec.Deprecated,
+	}
+
+	for _, name := range spec.Security {
+		op.Security = append(op.Security, map[string][]string{name: {}})
+	}
+
+	declared := map[string]bool{}
+
+	if spec.Request != nil {
+		t := reflect.TypeOf(spec.Request)
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+
+		if t.Kind() == reflect.Struct {
+			op.Parameters = b.parameters(t)
+			for _, p := range op.Parameters {
+				declared[p.In+":"+p.Name] = true
+			}
+			op.RequestBody = b.requestBody(t, spec.Method)
+		}
+	}
+
+	for _, name := range pathParams(spec.Path) {
+		if !declared["path:"+name] {
+			op.Parameters = append(op.Parameters, &Parameter{Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"}})
+		}
+	}
+
+	if len(spec.Responses) == 0 {
+		op.Responses["200"] = &Response{Description: http.StatusText(http.StatusOK)}
+	}
+
+	for status, resp := range spec.Responses {
+		description := resp.Description
+		if description == "" {
+			description = http.StatusText(status)
+		}
+
+		response := &Response{Description: description}
+		if resp.Body != nil {
+			contentType := "application/json"
+			if _, ok := resp.Body.(apierror.Problem); ok {
+				contentType = apierror.ProblemContentType
+			}
+			response.Content = map[string]MediaType{contentType: {Schema: b.schemaOfValue(resp.Body)}}
+		}
+
+		op.Responses[strconv.Itoa(status)] = response
+	}
+
+	return op
+}
+
+func (b *schemaBuilder) parameters(t reflect.Type) []*Parameter {
+	params := []*Parameter{}
+
+	eachField(t, func(field reflect.StructField) {
+		source, name := bindingOf(field)
+		if source == "" || source == "form" {
+			return
+		}
+
+		schema := b.schemaOf(field.Type)
+		schema.Nullable = false
+		if def, ok := field.Tag.Lookup("default"); ok {
+			schema.Default = parseDefault(schema, def)
+		}
+
+		required := applyRules(schema, field.Tag.Get("validate"))
+
+		params = append(params, &Parameter{
+			Name:        name,
+			In:          source,
+			Description: field.Tag.Get("description"),
+			Required:    required || source == "path",
+			Schema:      schema,
+		})
+	})
+
+	return params
+}
+
+func (b *schemaBuilder) requestBody(t reflect.Type, method string) *RequestBody {
+	if method == http.MethodGet || method == http.MethodHead || method == http.MethodDelete {
+		return nil
+	}
+
+	form, body, params := 0, 0, 0
+	eachField(t, func(field reflect.StructField) {
+		switch source, _ := bindingOf(field); source {
+		case "form":
+			form++
+		case "":
+			if name, ok := jsonName(field); ok && name != "" {
+				body++
+			}
+		default:
+			params++
+		}
+	})
+
+	if form > 0 {
+		schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+		eachField(t, func(field reflect.StructField) {
+			if source, name := bindingOf(field); source == "form" {
+				schema.Properties[name] = b.schemaOf(field.Type)
+				if applyRules(schema.Properties[name], field.Tag.Get("validate")) {
+					schema.Required = append(schema.Required, name)
+				}
+			}
+		})
+
+		return &RequestBody{Required: true, Content: map[string]MediaType{"application/x-www-form-urlencoded": {Schema: schema}}}
+	}
+
+	if body == 0 {
+		return nil
+	}
+
+	var schema *Schema
+	if params == 0 && t.Name() != "" {
+		schema = b.ref(t)
+	} else {
+		schema = b.structSchema(t, func(field reflect.StructField) bool {
+			source, _ := bindingOf(field)
+			return source == ""
+		})
+	}
+
+	return &RequestBody{Required: true, Content: map[string]MediaType{"application/json": {Schema: schema}}}
+}
+
+// specPath converts the router pattern into the OpenAPI path, {path...} and * become {path} and {wildcard}
+func specPath(pattern string) string {
+	segments := strings.Split(pattern, "/")
+	for i, seg := range segments {
+		if seg == "*" {
+			segments[i] = "{wildcard}"
+		} else if strings.HasSuffix(seg, "...}") {
+			segments[i] = strings.TrimSuffix(seg, "...}") + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func pathParams(pattern string) []string {
+	names := []string{}
+	for _, seg := range strings.Split(specPath(pattern), "/") {
+		if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+			names = append(names, seg[1:len(seg)-1])
+		}
+	}
+	return names
+}
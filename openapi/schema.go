@@ -0,0 +1,301 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/helderfarias/go-api-kit/endpoint"
+	"github.com/helderfarias/go-api-kit/validation"
+)
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	rawType      = reflect.TypeOf(json.RawMessage{})
+	pagingType   = reflect.TypeOf(endpoint.EntityPaging{})
+	cursorType   = reflect.TypeOf(endpoint.EntityCursorPaging{})
+	bindingTags  = []string{"path", "query", "header", "form"}
+	integerKinds = map[reflect.Kind]string{
+		reflect.Int: "int64", reflect.Int8: "int32", reflect.Int16: "int32", reflect.Int32: "int32", reflect.Int64: "int64",
+		reflect.Uint: "int64", reflect.Uint8: "int32", reflect.Uint16: "int32", reflect.Uint32: "int64", reflect.Uint64: "int64",
+	}
+)
+
+// schemaBuilder builds the schemas of the Go types, named structs are registered as components
+type schemaBuilder struct {
+	schemas map[string]*Schema
+	types   map[string]reflect.Type
+}
+
+func newSchemaBuilder() *schemaBuilder {
+	return &schemaBuilder{schemas: map[string]*Schema{}, types: map[string]reflect.Type{}}
+}
+
+// schemaOfValue schema of the value, paged data (endpoint.EntityPaging and EntityCursorPaging)
+// is described with the type of its Data
+func (b *schemaBuilder) schemaOfValue(v interface{}) *Schema {
+	switch paged := v.(type) {
+	case endpoint.EntityPaging:
+		return &Schema{
+			Type:       "object",
+			Properties: map[string]*Schema{"data": b.schemaOfValue(paged.Data), "paging": b.schemaOf(reflect.TypeOf(paged.Paging))},
+			Required:   []string{"data", "paging"},
+		}
+	case endpoint.EntityCursorPaging:
+		return &Schema{
+			Type:       "object",
+			Properties: map[string]*Schema{"data": b.schemaOfValue(paged.Data), "paging": b.schemaOf(reflect.TypeOf(paged.Paging))},
+			Required:   []string{"data", "paging"},
+		}
+	case nil:
+		return &Schema{}
+	}
+
+	return b.schemaOf(reflect.TypeOf(v))
+}
+
+func (b *schemaBuilder) schemaOf(t reflect.Type) *Schema {
+	if t.Kind() == reflect.Ptr {
+		s := b.schemaOf(t.Elem())
+		if s.Ref != "" {
+			// siblings of $ref are ignored by OpenAPI 3.0
+			return &Schema{AllOf: []*Schema{s}, Nullable: true}
+		}
+		s.Nullable = true
+		return s
+	}
+
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case rawType:
+		return &Schema{}
+	}
+
+	if format, ok := integerKinds[t.Kind()]; ok {
+		s := &Schema{Type: "integer", Format: format}
+		if strings.HasPrefix(t.Kind().String(), "uint") {
+			s.Minimum = float(0)
+		}
+		return s
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: b.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: b.schemaOf(t.Elem())}
+	case reflect.Struct:
+		if t == pagingType || t == cursorType || t.Name() == "" {
+			return b.structSchema(t, nil)
+		}
+		return b.ref(t)
+	}
+
+	return &Schema{}
+}
+
+// ref registers the struct as component, types with the same name of different packages
+// are prefixed with the package name
+func (b *schemaBuilder) ref(t reflect.Type) *Schema {
+	name := t.Name()
+	if other, ok := b.types[name]; ok && other != t {
+		name = strings.Replace(t.PkgPath(), "/", ".", -1) + "." + name
+	}
+
+	if _, ok := b.types[name]; !ok {
+		b.types[name] = t
+		b.schemas[name] = &Schema{}
+		*b.schemas[name] = *b.structSchema(t, nil)
+	}
+
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// structSchema object with the fields accepted by include (all when nil), embedded
+// structs without json tag are flattened like encoding/json does
+func (b *schemaBuilder) structSchema(t reflect.Type, include func(field reflect.StructField) bool) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+
+	eachField(t, func(field reflect.StructField) {
+		if include != nil && !include(field) {
+			return
+		}
+
+		name, ok := jsonName(field)
+		if !ok {
+			return
+		}
+
+		property := b.schemaOf(field.Type)
+		if description := field.Tag.Get("description"); description != "" && property.Ref == "" {
+			property.Description = description
+		}
+		if def, ok := field.Tag.Lookup("default"); ok && property.Ref == "" {
+			property.Default = parseDefault(property, def)
+		}
+
+		if applyRules(property, field.Tag.Get("validate")) {
+			s.Required = append(s.Required, name)
+		}
+
+		s.Properties[name] = property
+	})
+
+	return s
+}
+
+// applyRules maps the validation rules to the schema, returns true when the field is required
+func applyRules(s *Schema, rules string) bool {
+	required := false
+
+	for _, rule := range validation.SplitRules(rules) {
+		if rule == "dive" {
+			break
+		}
+
+		param := ""
+		if idx := strings.Index(rule, "="); idx >= 0 {
+			rule, param = rule[:idx], rule[idx+1:]
+		}
+
+		n, _ := strconv.ParseFloat(param, 64)
+
+		switch rule {
+		case "required":
+			required = true
+		case "min", "max", "len":
+			setBound(s, rule, n)
+		case "email":
+			s.Format = "email"
+		case "oneof":
+			for _, v := range strings.Fields(param) {
+				s.Enum = append(s.Enum, parseDefault(s, v))
+			}
+		case "regex":
+			s.Pattern = param
+		}
+	}
+
+	return required
+}
+
+func setBound(s *Schema, rule string, n float64) {
+	i := int(n)
+
+	switch s.Type {
+	case "string":
+		if rule != "max" {
+			s.MinLength = &i
+		}
+		if rule != "min" {
+			s.MaxLength = &i
+		}
+	case "array", "object":
+		if rule != "max" {
+			s.MinItems = &i
+		}
+		if rule != "min" {
+			s.MaxItems = &i
+		}
+	default:
+		if rule != "max" {
+			s.Minimum = float(n)
+		}
+		if rule != "min" {
+			s.Maximum = float(n)
+		}
+	}
+}
+
+// parseDefault typed value of the tag value for the schema type
+func parseDefault(s *Schema, value string) interface{} {
+	switch s.Type {
+	case "integer":
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return n
+		}
+	case "number":
+		if n, err := strconv.ParseFloat(value, 64); err == nil {
+			return n
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return value
+}
+
+// eachField exported fields of the struct, flattening embedded structs without json tag
+func eachField(t reflect.Type, fn func(field reflect.StructField)) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		if field.Anonymous && field.Tag.Get("json") == "" {
+			ft := field.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				eachField(ft, fn)
+				continue
+			}
+		}
+
+		if field.PkgPath != "" {
+			continue
+		}
+
+		fn(field)
+	}
+}
+
+// jsonName name of the field in the JSON document, false for skipped fields
+func jsonName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+
+	name := strings.Split(tag, ",")[0]
+	if name == "" {
+		name = field.Name
+	}
+
+	return name, true
+}
+
+// bindingOf source (path, query, header, form) and name of binding tagged fields
+func bindingOf(field reflect.StructField) (string, string) {
+	for _, source := range bindingTags {
+		if tag, ok := field.Tag.Lookup(source); ok {
+			name := strings.Split(tag, ",")[0]
+			if name == "-" {
+				return "", ""
+			}
+			if name == "" {
+				name = field.Name
+			}
+			return source, name
+		}
+	}
+	return "", ""
+}
+
+func float(n float64) *float64 {
+	return &n
+}
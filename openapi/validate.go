@@ -0,0 +1,420 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/helderfarias/go-api-kit/apierror"
+	"github.com/helderfarias/go-api-kit/httptransport"
+	"github.com/helderfarias/go-api-kit/validation"
+)
+
+// Validator checks requests against the document of the registry
+type Validator struct {
+	doc         *Document
+	routes      []route
+	patterns    map[string]*regexp.Regexp
+	maxBodySize int64
+}
+
+// ValidatorOptions validator configurations
+type ValidatorOptions struct {
+	// MaxBodySize larger bodies are rejected with 413, defaults to 1MB
+	MaxBodySize int64
+}
+
+type route struct {
+	method   string
+	segments []string
+	op       *Operation
+}
+
+// Validator constructs a validator of the current document, endpoints added later aren't checked.
+// Panics when a schema has an invalid pattern.
+func (r *Registry) Validator(options ...ValidatorOptions) *Validator {
+	opt := ValidatorOptions{}
+	if len(options) >= 1 {
+		opt = options[0]
+	}
+
+	if opt.MaxBodySize == 0 {
+		opt.MaxBodySize = 1024 * 1024
+	}
+
+	doc := r.Document()
+
+	v := &Validator{doc: doc, patterns: map[string]*regexp.Regexp{}, maxBodySize: opt.MaxBodySize}
+	for path, item := range doc.Paths {
+		for method, op := range *item {
+			v.routes = append(v.routes, route{method: strings.ToUpper(method), segments: strings.Split(strings.Trim(path, "/"), "/"), op: op})
+
+			for _, p := range op.Parameters {
+				v.compile(p.Schema)
+			}
+			if op.RequestBody != nil {
+				for _, content := range op.RequestBody.Content {
+					v.compile(content.Schema)
+				}
+			}
+		}
+	}
+
+	for _, s := range doc.Components.Schemas {
+		v.compile(s)
+	}
+
+	return v
+}
+
+// compile compiles the patterns of the schema and its children
+func (v *Validator) compile(s *Schema) {
+	if s == nil {
+		return
+	}
+
+	if s.Pattern != "" {
+		if _, ok := v.patterns[s.Pattern]; !ok {
+			re, err := regexp.Compile(s.Pattern)
+			if err != nil {
+				panic(fmt.Sprintf("openapi: invalid pattern %q: %v", s.Pattern, err))
+			}
+			v.patterns[s.Pattern] = re
+		}
+	}
+
+	v.compile(s.Items)
+	v.compile(s.AdditionalProperties)
+	for _, child := range s.AllOf {
+		v.compile(child)
+	}
+	for _, child := range s.Properties {
+		v.compile(child)
+	}
+}
+
+// Validate checks the parameters and the JSON body of the request, requests of undeclared
+// operations are accepted. Errors are 422 *apierror.Error with validation.Errors, or 415
+// for bodies of undeclared content types.
+func (v *Validator) Validate(r *http.Request) error {
+	op, pathValues, ok := v.match(r)
+	if !ok {
+		return nil
+	}
+
+	errs := validation.Errors{}
+
+	for _, p := range op.Parameters {
+		var values []string
+		switch p.In {
+		case "path":
+			values = []string{pathValues[p.Name]}
+		case "query":
+			values = r.URL.Query()[p.Name]
+		case "header":
+			values = r.Header[http.CanonicalHeaderKey(p.Name)]
+		}
+
+		if len(values) == 0 || (len(values) == 1 && values[0] == "") {
+			if p.Required {
+				errs = append(errs, fieldError(p.Name, "required", "", fmt.Sprintf("%v is required", p.Name)))
+			}
+			continue
+		}
+
+		if p.Schema.Type == "array" && p.Schema.Items != nil {
+			if len(values) == 1 {
+				values = strings.Split(values[0], ",")
+			}
+			for i, value := range values {
+				v.check(fmt.Sprintf("%v[%d]", p.Name, i), p.Schema.Items, parseParam(p.Schema.Items, value), &errs)
+			}
+			continue
+		}
+
+		v.check(p.Name, p.Schema, parseParam(p.Schema, values[0]), &errs)
+	}
+
+	if err := v.validateBody(r, op, &errs); err != nil {
+		return err
+	}
+
+	if len(errs) > 0 {
+		return apierror.Validation("request does not match the API schema", errs)
+	}
+
+	return nil
+}
+
+// Middleware rejects requests not matching the document, the errors are written by
+// httptransport.DefaultErrorEncoder
+func (v *Validator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := v.Validate(r); err != nil {
+			httptransport.DefaultErrorEncoder(r.Context(), err, w)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (v *Validator) match(r *http.Request) (*Operation, map[string]string, bool) {
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+
+	var best *route
+	var bestValues map[string]string
+	bestScore := -1
+
+	for i := range v.routes {
+		rt := &v.routes[i]
+		if rt.method != r.Method || len(rt.segments) != len(segments) {
+			continue
+		}
+
+		values, score, ok := matchSegments(rt.segments, segments)
+		if ok && score > bestScore {
+			best, bestValues, bestScore = rt, values, score
+		}
+	}
+
+	if best == nil {
+		return nil, nil, false
+	}
+
+	return best.op, bestValues, true
+}
+
+// matchSegments the score is the number of literal segments, so the most specific route wins
+func matchSegments(pattern, path []string) (map[string]string, int, bool) {
+	values := map[string]string{}
+	score := 0
+
+	for i, seg := range pattern {
+		if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+			values[seg[1:len(seg)-1]] = path[i]
+			continue
+		}
+
+		if seg != path[i] {
+			return nil, 0, false
+		}
+		score++
+	}
+
+	return values, score, true
+}
+
+func (v *Validator) validateBody(r *http.Request, op *Operation, errs *validation.Errors) error {
+	if op.RequestBody == nil || r.Body == nil {
+		return nil
+	}
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(nil, r.Body, v.maxBodySize))
+	if err != nil && int64(len(body)) >= v.maxBodySize {
+		return apierror.New(http.StatusRequestEntityTooLarge, "request_too_large", "request body too large")
+	}
+	if err != nil {
+		return err
+	}
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	if len(bytes.TrimSpace(body)) == 0 {
+		if op.RequestBody.Required {
+			*errs = append(*errs, fieldError("body", "required", "", "body is required"))
+		}
+		return nil
+	}
+
+	mediaType := "application/json"
+	if contentType := r.Header.Get("Content-Type"); contentType != "" {
+		mediaType, _, _ = mime.ParseMediaType(contentType)
+	}
+
+	content, ok := op.RequestBody.Content[mediaType]
+	if !ok {
+		return apierror.New(http.StatusUnsupportedMediaType, "unsupported_media_type", "unsupported content type "+mediaType)
+	}
+
+	if mediaType != "application/json" {
+		return nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+
+	var value interface{}
+	if err := dec.Decode(&value); err != nil {
+		*errs = append(*errs, fieldError("body", "json", "", "body must be valid JSON"))
+		return nil
+	}
+
+	v.check("", content.Schema, value, errs)
+	return nil
+}
+
+// check validates the decoded JSON value (or parsed parameter) against the schema
+func (v *Validator) check(name string, s *Schema, value interface{}, errs *validation.Errors) {
+	if s == nil {
+		return
+	}
+
+	if s.Ref != "" {
+		v.check(name, v.doc.Components.Schemas[strings.TrimPrefix(s.Ref, "#/components/schemas/")], value, errs)
+		return
+	}
+
+	label := name
+	if label == "" {
+		label = "body"
+	}
+
+	if value == nil {
+		if !s.Nullable && s.Type != "" {
+			*errs = append(*errs, fieldError(label, "type", s.Type, fmt.Sprintf("%v must be a valid %v", label, s.Type)))
+		}
+		return
+	}
+
+	if len(s.AllOf) > 0 {
+		for _, child := range s.AllOf {
+			v.check(name, child, value, errs)
+		}
+		return
+	}
+
+	if !matchesType(s.Type, value) {
+		*errs = append(*errs, fieldError(label, "type", s.Type, fmt.Sprintf("%v must be a valid %v", label, s.Type)))
+		return
+	}
+
+	if len(s.Enum) > 0 && !inEnum(s.Enum, value) {
+		options := []string{}
+		for _, e := range s.Enum {
+			options = append(options, fmt.Sprint(e))
+		}
+		param := strings.Join(options, " ")
+		*errs = append(*errs, fieldError(label, "oneof", param, fmt.Sprintf("%v must be one of [%v]", label, param)))
+	}
+
+	switch s.Type {
+	case "string":
+		str := value.(string)
+		length := len([]rune(str))
+		if s.MinLength != nil && length < *s.MinLength {
+			*errs = append(*errs, fieldError(label, "min", strconv.Itoa(*s.MinLength), fmt.Sprintf("%v must be at least %v", label, *s.MinLength)))
+		}
+		if s.MaxLength != nil && length > *s.MaxLength {
+			*errs = append(*errs, fieldError(label, "max", strconv.Itoa(*s.MaxLength), fmt.Sprintf("%v must be at most %v", label, *s.MaxLength)))
+		}
+		if s.Pattern != "" {
+			if re, ok := v.patterns[s.Pattern]; ok && !re.MatchString(str) {
+				*errs = append(*errs, fieldError(label, "regex", s.Pattern, fmt.Sprintf("%v must match %v", label, s.Pattern)))
+			}
+		}
+	case "integer", "number":
+		n, _ := value.(json.Number).Float64()
+		if s.Minimum != nil && n < *s.Minimum {
+			*errs = append(*errs, fieldError(label, "min", fmt.Sprint(*s.Minimum), fmt.Sprintf("%v must be at least %v", label, *s.Minimum)))
+		}
+		if s.Maximum != nil && n > *s.Maximum {
+			*errs = append(*errs, fieldError(label, "max", fmt.Sprint(*s.Maximum), fmt.Sprintf("%v must be at most %v", label, *s.Maximum)))
+		}
+	case "array":
+		items := value.([]interface{})
+		if s.MinItems != nil && len(items) < *s.MinItems {
+			*errs = append(*errs, fieldError(label, "min", strconv.Itoa(*s.MinItems), fmt.Sprintf("%v must be at least %v", label, *s.MinItems)))
+		}
+		if s.MaxItems != nil && len(items) > *s.MaxItems {
+			*errs = append(*errs, fieldError(label, "max", strconv.Itoa(*s.MaxItems), fmt.Sprintf("%v must be at most %v", label, *s.MaxItems)))
+		}
+		for i, item := range items {
+			v.check(fmt.Sprintf("%v[%d]", name, i), s.Items, item, errs)
+		}
+	case "object":
+		object := value.(map[string]interface{})
+		for _, required := range s.Required {
+			if _, ok := object[required]; !ok {
+				field := join(name, required)
+				*errs = append(*errs, fieldError(field, "required", "", fmt.Sprintf("%v is required", field)))
+			}
+		}
+		for key, item := range object {
+			if property, ok := s.Properties[key]; ok {
+				v.check(join(name, key), property, item, errs)
+			} else if s.AdditionalProperties != nil {
+				v.check(join(name, key), s.AdditionalProperties, item, errs)
+			}
+		}
+	}
+}
+
+func matchesType(typ string, value interface{}) bool {
+	switch typ {
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "integer":
+		n, ok := value.(json.Number)
+		if !ok {
+			return false
+		}
+		_, err := n.Int64()
+		return err == nil
+	case "number":
+		_, ok := value.(json.Number)
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	}
+	return true
+}
+
+// parseParam converts the parameter into the JSON representation of its schema type,
+// invalid values are kept as string so the type check fails
+func parseParam(s *Schema, value string) interface{} {
+	switch s.Type {
+	case "integer", "number":
+		if _, err := strconv.ParseFloat(value, 64); err == nil {
+			return json.Number(value)
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return value
+}
+
+func inEnum(enum []interface{}, value interface{}) bool {
+	for _, e := range enum {
+		if fmt.Sprint(e) == fmt.Sprint(value) {
+			return true
+		}
+	}
+	return false
+}
+
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func fieldError(field, rule, param, message string) validation.FieldError {
+	return validation.FieldError{Field: field, Rule: rule, Param: param, Message: message}
+}
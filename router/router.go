@@ -0,0 +1,337 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/helderfarias/go-api-kit/endpoint"
+	"github.com/helderfarias/go-api-kit/httptransport"
+	"github.com/helderfarias/go-api-kit/uri"
+)
+
+// Router matches requests by method and pattern, patterns are made of literal
+// segments, params like /users/{id} and a trailing wildcard /files/{path...} or /files/*
+type Router struct {
+	routes           []*Route
+	named            map[string]*Route
+	NotFound         http.Handler
+	MethodNotAllowed http.Handler
+}
+
+// Group routes sharing a prefix and endpoint middlewares
+type Group struct {
+	router      *Router
+	prefix      string
+	middlewares []endpoint.Middleware
+}
+
+// Route a registered handler
+type Route struct {
+	router   *Router
+	method   string
+	pattern  string
+	segments []string
+	handler  http.Handler
+	name     string
+}
+
+// Params path params of the matched route
+type Params map[string]string
+
+type contextKey string
+
+const paramsContextKey contextKey = "router.params"
+
+// NewRouter constructs an empty router
+func NewRouter() *Router {
+	return &Router{
+		named:            map[string]*Route{},
+		NotFound:         http.HandlerFunc(notFound),
+		MethodNotAllowed: http.HandlerFunc(methodNotAllowed),
+	}
+}
+
+// Handle registers the handler for the method and pattern
+func (r *Router) Handle(method, pattern string, h http.Handler) *Route {
+	return r.Group("").Handle(method, pattern, h)
+}
+
+// Endpoint registers the endpoint served by the HTTP transport
+func (r *Router) Endpoint(method, pattern string, e endpoint.Endpoint, dec httptransport.DecodeRequestFunc, enc httptransport.EncodeResponseFunc, options ...httptransport.ServerOption) *Route {
+	return r.Group("").Endpoint(method, pattern, e, dec, enc, options...)
+}
+
+// Group creates a group of routes under the prefix
+func (r *Router) Group(prefix string, middlewares ...endpoint.Middleware) *Group {
+	return &Group{router: r, prefix: prefix, middlewares: middlewares}
+}
+
+// URL reverse URL of the named route, e.g. URL("user", map[string]interface{}{"id": 10}) => /users/10.
+// Param values are escaped, wildcard values keep their slashes.
+func (r *Router) URL(name string, params map[string]interface{}) (*uri.URI, error) {
+	route, ok := r.named[name]
+	if !ok {
+		return nil, fmt.Errorf("route %q not found", name)
+	}
+
+	u := uri.NewBuildURI("")
+	for _, seg := range route.segments {
+		if key, ok := wildcardName(seg); ok {
+			value, ok := params[key]
+			if !ok {
+				return nil, fmt.Errorf("route %q, missing param %q", name, key)
+			}
+
+			parts := strings.Split(strings.Trim(fmt.Sprint(value), "/"), "/")
+			for i, part := range parts {
+				parts[i] = url.PathEscape(part)
+			}
+			u.Path(strings.Join(parts, "/"))
+			continue
+		}
+
+		if key, ok := paramName(seg); ok {
+			value, ok := params[key]
+			if !ok {
+				return nil, fmt.Errorf("route %q, missing param %q", name, key)
+			}
+			u.Path(url.PathEscape(fmt.Sprint(value)))
+			continue
+		}
+		u.Path(seg)
+	}
+
+	return u, nil
+}
+
+// ServeHTTP implements http.Handler, OPTIONS requests without route are answered with Allow
+func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	path := requestPath(req)
+
+	var best *Route
+	var params Params
+	allowed := map[string]bool{}
+
+	for _, route := range r.routes {
+		p, ok := route.match(path)
+		if !ok {
+			continue
+		}
+
+		if route.method != req.Method && !(req.Method == http.MethodHead && route.method == http.MethodGet) {
+			allowed[route.method] = true
+			continue
+		}
+
+		if best == nil || route.specificity() > best.specificity() {
+			best, params = route, p
+		}
+	}
+
+	if best == nil {
+		if len(allowed) > 0 {
+			if allowed[http.MethodGet] {
+				allowed[http.MethodHead] = true
+			}
+			allowed[http.MethodOptions] = true
+
+			methods := []string{}
+			for method := range allowed {
+				methods = append(methods, method)
+			}
+			sort.Strings(methods)
+
+			w.Header().Set("Allow", strings.Join(methods, ", "))
+			if req.Method == http.MethodOptions {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			r.MethodNotAllowed.ServeHTTP(w, req)
+			return
+		}
+
+		r.NotFound.ServeHTTP(w, req)
+		return
+	}
+
+	ctx := context.WithValue(req.Context(), paramsContextKey, params)
+	best.handler.ServeHTTP(w, req.WithContext(ctx))
+}
+
+// Handle registers the handler for the method and pattern under the group prefix,
+// the group middlewares only apply to endpoints
+func (g *Group) Handle(method, pattern string, h http.Handler) *Route {
+	full := "/" + strings.Trim(strings.TrimRight(g.prefix, "/")+"/"+strings.TrimLeft(pattern, "/"), "/")
+
+	route := &Route{
+		router:   g.router,
+		method:   strings.ToUpper(method),
+		pattern:  full,
+		segments: splitPath(full),
+		handler:  h,
+	}
+
+	g.router.routes = append(g.router.routes, route)
+	return route
+}
+
+// Endpoint registers the endpoint wrapped by the group middlewares
+func (g *Group) Endpoint(method, pattern string, e endpoint.Endpoint, dec httptransport.DecodeRequestFunc, enc httptransport.EncodeResponseFunc, options ...httptransport.ServerOption) *Route {
+	for i := len(g.middlewares) - 1; i >= 0; i-- {
+		e = g.middlewares[i](e)
+	}
+
+	return g.Handle(method, pattern, httptransport.NewServer(e, dec, enc, options...))
+}
+
+// Group creates a sub group inheriting the prefix and middlewares
+func (g *Group) Group(prefix string, middlewares ...endpoint.Middleware) *Group {
+	all := append([]endpoint.Middleware{}, g.middlewares...)
+	all = append(all, middlewares...)
+
+	return &Group{
+		router:      g.router,
+		prefix:      strings.TrimRight(g.prefix, "/") + "/" + strings.Trim(prefix, "/"),
+		middlewares: all,
+	}
+}
+
+// Name names the route for reverse URL generation, panics when the name is taken
+func (r *Route) Name(name string) *Route {
+	if other, ok := r.router.named[name]; ok && other != r {
+		panic(fmt.Sprintf("router: route name %q already used by %v %v", name, other.method, other.pattern))
+	}
+
+	r.name = name
+	r.router.named[name] = r
+	return r
+}
+
+// Pattern full pattern of the route
+func (r *Route) Pattern() string {
+	return r.pattern
+}
+
+// Param path param of the matched route
+func Param(ctx context.Context, name string) string {
+	return ParamsFromContext(ctx)[name]
+}
+
+// ParamsFromContext path params of the matched route
+func ParamsFromContext(ctx context.Context) Params {
+	if ctx == nil {
+		return Params{}
+	}
+
+	params, ok := ctx.Value(paramsContextKey).(Params)
+	if !ok {
+		return Params{}
+	}
+	return params
+}
+
+func (r *Route) match(path []string) (Params, bool) {
+	params := Params{}
+
+	for i, seg := range r.segments {
+		if name, ok := wildcardName(seg); ok {
+			params[name] = strings.Join(path[i:], "/")
+			return params, true
+		}
+
+		if i >= len(path) {
+			return nil, false
+		}
+
+		if name, ok := paramName(seg); ok {
+			if path[i] == "" {
+				return nil, false
+			}
+			params[name] = path[i]
+			continue
+		}
+
+		if seg != path[i] {
+			return nil, false
+		}
+	}
+
+	if len(path) != len(r.segments) {
+		return nil, false
+	}
+
+	return params, true
+}
+
+// specificity literal segments weight more than params and params more than wildcards
+func (r *Route) specificity() int {
+	score := 0
+	for _, seg := range r.segments {
+		score *= 3
+		if _, ok := wildcardName(seg); ok {
+			continue
+		}
+		if _, ok := paramName(seg); ok {
+			score++
+			continue
+		}
+		score += 2
+	}
+	return score
+}
+
+func paramName(seg string) (string, bool) {
+	if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") && !strings.HasSuffix(seg, "...}") {
+		return seg[1 : len(seg)-1], true
+	}
+	return "", false
+}
+
+func wildcardName(seg string) (string, bool) {
+	if seg == "*" {
+		return "*", true
+	}
+	if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "...}") {
+		return seg[1 : len(seg)-4], true
+	}
+	return "", false
+}
+
+// requestPath segments of the escaped request path, unescaped one by one so an
+// escaped slash stays inside its segment
+func requestPath(req *http.Request) []string {
+	path := splitPath(req.URL.EscapedPath())
+	for i, seg := range path {
+		if unescaped, err := url.PathUnescape(seg); err == nil {
+			path[i] = unescaped
+		}
+	}
+	return path
+}
+
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return []string{}
+	}
+	return strings.Split(path, "/")
+}
+
+func notFound(w http.ResponseWriter, r *http.Request) {
+	writeMessage(w, http.StatusNotFound, "not found")
+}
+
+func methodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	writeMessage(w, http.StatusMethodNotAllowed, "method not allowed")
+}
+
+func writeMessage(w http.ResponseWriter, code int, message string) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]string{"message": message})
+}
@@ -0,0 +1,104 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/helderfarias/go-api-kit/endpoint"
+	"github.com/helderfarias/go-api-kit/httptransport"
+	"github.com/stretchr/testify/assert"
+)
+
+func serve(r http.Handler, method, path string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+	return rec
+}
+
+func echoParams(ctx context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+	return endpoint.Response(200, ParamsFromContext(ctx)), nil
+}
+
+func TestRouterMatchParamsAndWildcards(t *testing.T) {
+	r := NewRouter()
+	r.Endpoint("GET", "/users/{id}", echoParams, httptransport.NopRequestDecoder, httptransport.EncodeJSONResponse)
+	r.Endpoint("GET", "/users/me", func(ctx context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+		return endpoint.Response(200, "me"), nil
+	}, httptransport.NopRequestDecoder, httptransport.EncodeJSONResponse)
+	r.Endpoint("GET", "/files/{path...}", echoParams, httptransport.NopRequestDecoder, httptransport.EncodeJSONResponse)
+
+	assert.JSONEq(t, `{"id":"10"}`, serve(r, "GET", "/users/10").Body.String())
+	assert.JSONEq(t, `"me"`, serve(r, "GET", "/users/me").Body.String())
+	assert.JSONEq(t, `{"path":"a/b/c.txt"}`, serve(r, "GET", "/files/a/b/c.txt").Body.String())
+}
+
+func TestRouterNotFoundAndMethodNotAllowed(t *testing.T) {
+	r := NewRouter()
+	r.Endpoint("GET", "/users/{id}", echoParams, httptransport.NopRequestDecoder, httptransport.EncodeJSONResponse)
+	r.Endpoint("PUT", "/users/{id}", echoParams, httptransport.NopRequestDecoder, httptransport.EncodeJSONResponse)
+	r.Endpoint("GET", "/users/me", echoParams, httptransport.NopRequestDecoder, httptransport.EncodeJSONResponse)
+
+	notFound := serve(r, "GET", "/addresses")
+	notAllowed := serve(r, "DELETE", "/users/me")
+
+	assert.Equal(t, 404, notFound.Code)
+	assert.Equal(t, 405, notAllowed.Code)
+	assert.Equal(t, "GET, HEAD, OPTIONS, PUT", notAllowed.Header().Get("Allow"))
+
+	options := serve(r, "OPTIONS", "/users/10")
+	assert.Equal(t, 204, options.Code)
+	assert.Equal(t, "GET, HEAD, OPTIONS, PUT", options.Header().Get("Allow"))
+}
+
+func TestRouterGroupMiddlewaresAndReverseURL(t *testing.T) {
+	calls := []string{}
+	trace := func(name string) endpoint.Middleware {
+		return func(next endpoint.Endpoint) endpoint.Endpoint {
+			return func(ctx context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+				calls = append(calls, name)
+				return next(ctx, request)
+			}
+		}
+	}
+
+	r := NewRouter()
+	api := r.Group("/api", trace("api"))
+	v1 := api.Group("v1", trace("v1"))
+	v1.Endpoint("GET", "/users/{id}/addresses", echoParams, httptransport.NopRequestDecoder, httptransport.EncodeJSONResponse).Name("user.addresses")
+
+	rec := serve(r, "GET", "/api/v1/users/10/addresses")
+
+	assert.Equal(t, 200, rec.Code)
+	assert.Equal(t, []string{"api", "v1"}, calls)
+
+	u, err := r.URL("user.addresses", map[string]interface{}{"id": 10})
+	assert.Nil(t, err)
+	assert.Equal(t, "/api/v1/users/10/addresses?page=2", u.QueryParam("page", 2).String())
+}
+
+func TestRouterReverseURLEscapesParams(t *testing.T) {
+	r := NewRouter()
+	r.Endpoint("GET", "/users/{id}/files/{path...}", echoParams, httptransport.NopRequestDecoder, httptransport.EncodeJSONResponse).Name("user.file")
+	r.Handle("GET", "/static/*", http.NotFoundHandler()).Name("static")
+
+	u, err := r.URL("user.file", map[string]interface{}{"id": "a/b?c#d e", "path": "docs/my report.pdf"})
+	assert.Nil(t, err)
+	assert.Equal(t, "/users/a%2Fb%3Fc%23d%20e/files/docs/my%20report.pdf", u.String())
+	assert.JSONEq(t, `{"id":"a/b?c#d e","path":"docs/my report.pdf"}`, serve(r, "GET", u.String()).Body.String())
+
+	u, err = r.URL("static", map[string]interface{}{"*": "css/app.css"})
+	assert.Nil(t, err)
+	assert.Equal(t, "/static/css/app.css", u.String())
+
+	_, err = r.URL("static", map[string]interface{}{})
+	assert.EqualError(t, err, `route "static", missing param "*"`)
+}
+
+func TestRouterDuplicateNamePanics(t *testing.T) {
+	r := NewRouter()
+	r.Handle("GET", "/users/{id}", http.NotFoundHandler()).Name("user")
+
+	assert.Panics(t, func() { r.Handle("PUT", "/users/{id}", http.NotFoundHandler()).Name("user") })
+}
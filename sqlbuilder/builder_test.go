@@ -1,8 +1,10 @@
 package sqlbuilder
 
 import (
+	"net/url"
 	"testing"
 
+	"github.com/helderfarias/go-api-kit/endpoint"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -52,3 +54,19 @@ func TestShouldCreateQueryWithManyArgsOracle(t *testing.T) {
 	assert.NotNil(t, args)
 	assert.Equal(t, 3, len(args))
 }
+
+func TestShouldCreateKeysetPaginatedQuery(t *testing.T) {
+	codec := endpoint.NewCursorCodec([]byte("secret"))
+	opts := endpoint.PageOptions{Codec: codec, SortFields: map[string]string{"created": "created_at", "id": "id"}}
+
+	first, _ := endpoint.ParsePageRequest(url.Values{"sort": {"-created,id"}}, opts)
+	cursor, _ := codec.Encode(first.NewCursor([]interface{}{"2020-01-01", 10}, false))
+
+	p, err := endpoint.ParsePageRequest(url.Values{"cursor": {cursor}, "sort": {"-created,id"}, "limit": {"10"}}, opts)
+	assert.Nil(t, err)
+
+	sql, args := Paginate(Select("SELECT * FROM addresses").Where(Keyset(p)), p).Build()
+
+	assert.Equal(t, "SELECT * FROM addresses WHERE 1=1 AND ((created_at < ?) OR (created_at = ? AND id > ?)) ORDER BY created_at DESC, id ASC LIMIT ? OFFSET ?", sql)
+	assert.Equal(t, []interface{}{"2020-01-01", "2020-01-01", int64(10), int64(10), int64(0)}, args)
+}
@@ -0,0 +1,55 @@
+package sqlbuilder
+
+import (
+	"strings"
+
+	"github.com/helderfarias/go-api-kit/endpoint"
+)
+
+// Keyset condition selecting the rows after the cursor of the page request, expanded as
+// (a > ?) OR (a = ? AND b > ?) so it works with mixed directions and any dialect, e.g.
+// Select(sql).Where(Keyset(p))
+func Keyset(p endpoint.PageRequest) func(args Value) {
+	return func(args Value) {
+		if p.Cursor == nil || len(p.Sort) == 0 {
+			return
+		}
+
+		ors := []string{}
+		values := []interface{}{}
+
+		for i, s := range p.Sort {
+			ands := []string{}
+			for j := 0; j < i; j++ {
+				ands = append(ands, p.Sort[j].Column+" = ?")
+				values = append(values, p.Cursor.Keys[j])
+			}
+
+			op := ">"
+			if s.Desc != p.Cursor.Backward {
+				op = "<"
+			}
+
+			ands = append(ands, s.Column+" "+op+" ?")
+			values = append(values, p.Cursor.Keys[i])
+			ors = append(ors, "("+strings.Join(ands, " AND ")+")")
+		}
+
+		args.Add("AND ("+strings.Join(ors, " OR ")+")", values...)
+	}
+}
+
+// Paginate applies the order and the limit of the page request to the query, offset
+// pagination is only used without cursor
+func Paginate(w WhereBuilder, p endpoint.PageRequest) WhereBuilder {
+	if order := p.OrderBy(); order != "" {
+		w = w.OrderBy(order)
+	}
+
+	offset := p.Offset()
+	if p.Cursor != nil {
+		offset = 0
+	}
+
+	return w.SetPaginate(offset, p.Limit)
+}
@@ -0,0 +1,225 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+
+	"gopkg.in/resty.v1"
+)
+
+// Exporter sends the ended spans to a backend
+type Exporter interface {
+	Export(ctx context.Context, spans []SpanData) error
+	Shutdown(ctx context.Context) error
+}
+
+// StdoutExporter writes one JSON line per span, for development
+type StdoutExporter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// OTLPExporter sends the spans as OTLP/JSON over HTTP, e.g. to an OpenTelemetry collector
+type OTLPExporter struct {
+	client  *resty.Client
+	url     string
+	headers map[string]string
+}
+
+// OTLPOption sets an optional parameter for the OTLP exporter
+type OTLPOption func(e *OTLPExporter)
+
+type otlpAttribute struct {
+	Key   string                 `json:"key"`
+	Value map[string]interface{} `json:"value"`
+}
+
+type otlpEvent struct {
+	Name         string          `json:"name"`
+	TimeUnixNano string          `json:"timeUnixNano"`
+	Attributes   []otlpAttribute `json:"attributes,omitempty"`
+}
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId,omitempty"`
+	TraceState        string          `json:"traceState,omitempty"`
+	Name              string          `json:"name"`
+	Kind              SpanKind        `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	Events            []otlpEvent     `json:"events,omitempty"`
+	Status            Status          `json:"status"`
+}
+
+// NewStdoutExporter writes to w, e.g. os.Stdout
+func NewStdoutExporter(w io.Writer) *StdoutExporter {
+	return &StdoutExporter{w: w}
+}
+
+func (e *StdoutExporter) Export(ctx context.Context, spans []SpanData) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	enc := json.NewEncoder(e.w)
+	for _, span := range spans {
+		if err := enc.Encode(span); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (e *StdoutExporter) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// NewOTLPExporter posts to the traces url of the collector, e.g. http://localhost:4318/v1/traces
+func NewOTLPExporter(url string, options ...OTLPOption) *OTLPExporter {
+	e := &OTLPExporter{
+		client:  resty.New().SetDisableWarn(true),
+		url:     url,
+		headers: map[string]string{},
+	}
+
+	for _, o := range options {
+		o(e)
+	}
+
+	return e
+}
+
+// OTLPHeader sets a header of the requests, e.g. the collector api key
+func OTLPHeader(key, value string) OTLPOption {
+	return func(e *OTLPExporter) { e.headers[key] = value }
+}
+
+// OTLPClient sets the resty client used (timeouts, retries, TLS...)
+func OTLPClient(client *resty.Client) OTLPOption {
+	return func(e *OTLPExporter) { e.client = client }
+}
+
+func (e *OTLPExporter) Export(ctx context.Context, spans []SpanData) error {
+	resp, err := e.client.R().
+		SetContext(ctx).
+		SetHeaders(e.headers).
+		SetHeader("Content-Type", "application/json").
+		SetBody(otlpRequest(spans)).
+		Post(e.url)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode() >= 300 {
+		return fmt.Errorf("otlp collector replied %v: %s", resp.StatusCode(), resp.Body())
+	}
+
+	return nil
+}
+
+func (e *OTLPExporter) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// otlpRequest groups the spans by service into resource spans
+func otlpRequest(spans []SpanData) map[string]interface{} {
+	byService := map[string][]otlpSpan{}
+
+	for _, span := range spans {
+		s := otlpSpan{
+			TraceID:           span.TraceID.String(),
+			SpanID:            span.SpanID.String(),
+			TraceState:        span.TraceState,
+			Name:              span.Name,
+			Kind:              span.Kind,
+			StartTimeUnixNano: strconv.FormatInt(span.Start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(span.End.UnixNano(), 10),
+			Attributes:        otlpAttributes(span.Attributes),
+			Status:            span.Status,
+		}
+
+		if span.ParentSpanID.IsValid() {
+			s.ParentSpanID = span.ParentSpanID.String()
+		}
+
+		for _, event := range span.Events {
+			s.Events = append(s.Events, otlpEvent{
+				Name:         event.Name,
+				TimeUnixNano: strconv.FormatInt(event.Time.UnixNano(), 10),
+				Attributes:   otlpAttributes(event.Attributes),
+			})
+		}
+
+		byService[span.Service] = append(byService[span.Service], s)
+	}
+
+	services := []string{}
+	for service := range byService {
+		services = append(services, service)
+	}
+	sort.Strings(services)
+
+	resourceSpans := []interface{}{}
+	for _, service := range services {
+		resource := map[string]interface{}{}
+		if service != "" {
+			resource["attributes"] = otlpAttributes(map[string]interface{}{"service.name": service})
+		}
+
+		resourceSpans = append(resourceSpans, map[string]interface{}{
+			"resource": resource,
+			"scopeSpans": []interface{}{
+				map[string]interface{}{
+					"scope": map[string]interface{}{"name": "github.com/helderfarias/go-api-kit/tracing"},
+					"spans": byService[service],
+				},
+			},
+		})
+	}
+
+	return map[string]interface{}{"resourceSpans": resourceSpans}
+}
+
+func otlpAttributes(attributes map[string]interface{}) []otlpAttribute {
+	keys := []string{}
+	for k := range attributes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	result := []otlpAttribute{}
+	for _, k := range keys {
+		result = append(result, otlpAttribute{Key: k, Value: otlpValue(attributes[k])})
+	}
+
+	return result
+}
+
+func otlpValue(v interface{}) map[string]interface{} {
+	switch value := v.(type) {
+	case string:
+		return map[string]interface{}{"stringValue": value}
+	case bool:
+		return map[string]interface{}{"boolValue": value}
+	case int:
+		return map[string]interface{}{"intValue": strconv.FormatInt(int64(value), 10)}
+	case int32:
+		return map[string]interface{}{"intValue": strconv.FormatInt(int64(value), 10)}
+	case int64:
+		return map[string]interface{}{"intValue": strconv.FormatInt(value, 10)}
+	case float32:
+		return map[string]interface{}{"doubleValue": float64(value)}
+	case float64:
+		return map[string]interface{}{"doubleValue": value}
+	}
+
+	return map[string]interface{}{"stringValue": fmt.Sprint(v)}
+}
@@ -0,0 +1,294 @@
+package tracing
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/helderfarias/go-api-kit/cache"
+	"github.com/helderfarias/go-api-kit/constants"
+	"github.com/helderfarias/go-api-kit/cron"
+	"github.com/helderfarias/go-api-kit/endpoint"
+	"github.com/helderfarias/go-api-kit/mb"
+	"github.com/helderfarias/go-api-kit/service"
+	wrapper "github.com/helderfarias/sqlx-wrapper/db"
+	"github.com/jmoiron/sqlx"
+	"github.com/nats-io/nats.go"
+)
+
+type tracedCache struct {
+	cache.CacheServer
+	ctx    context.Context
+	system string
+}
+
+// tracedRedisCache keeps the Pinger and ScriptRunner of the Redis cache visible
+type tracedRedisCache struct {
+	*tracedCache
+	redis redisCache
+}
+
+type redisCache interface {
+	cache.Pinger
+	cache.ScriptRunner
+}
+
+type tracedUnitOfWork struct {
+	ctx      context.Context
+	delegate wrapper.UnitOfWork
+}
+
+// Publish publishes in a producer span, the trace context travels in the message headers
+func Publish(ctx context.Context, pub mb.Publisher, stream, subj string, sender interface{}, opts ...mb.PubOpts) (interface{}, error) {
+	ctx, span := Start(ctx, "publish "+subj, WithKind(SpanKindProducer), WithAttributes(map[string]interface{}{
+		"messaging.system":      "nats",
+		"messaging.destination": subj,
+	}))
+	defer span.End()
+
+	headers := map[string]string{}
+	Inject(ctx, headerCarrier(headers))
+	for k, v := range headers {
+		opts = append(opts, mb.Header(k, v))
+	}
+
+	ack, err := pub.Publish(stream, subj, sender, opts...)
+	span.RecordError(err)
+
+	return ack, err
+}
+
+// Consumer handles the *nats.Msg requests in a consumer span, child of the publisher span, e.g.
+// sub.Subscribe(stream, subject, consumer, tracing.Consumer(subject, e))
+func Consumer(subject string, next endpoint.Endpoint) endpoint.Endpoint {
+	return func(parent context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+		if msg, ok := request.(*nats.Msg); ok && msg.Header != nil {
+			parent = Extract(parent, msg.Header)
+		}
+
+		ctx, span := Start(parent, "consume "+subject, WithKind(SpanKindConsumer), WithAttributes(map[string]interface{}{
+			"messaging.system":      "nats",
+			"messaging.destination": subject,
+		}))
+		defer span.End()
+
+		resp, err := next(ctx, request)
+		span.RecordError(err)
+
+		return resp, err
+	}
+}
+
+// Job runs each execution of the cron task as the root span of a new trace, panics are recorded
+// and propagated
+func Job(name string, task cron.Scheduled) cron.Scheduled {
+	return func() {
+		_, span := Start(context.Background(), "cron "+name, WithAttributes(map[string]interface{}{"cron.job": name}))
+
+		defer func() {
+			if r := recover(); r != nil {
+				span.RecordError(fmt.Errorf("panic: %v", r))
+				span.End()
+				panic(r)
+			}
+			span.End()
+		}()
+
+		task()
+	}
+}
+
+// Cache the server recording a client span per operation, children of the span in ctx
+func Cache(ctx context.Context, server cache.CacheServer) cache.CacheServer {
+	if rc, ok := server.(redisCache); ok {
+		return &tracedRedisCache{tracedCache: &tracedCache{CacheServer: server, ctx: ctx, system: "redis"}, redis: rc}
+	}
+
+	return &tracedCache{CacheServer: server, ctx: ctx, system: "memory"}
+}
+
+// UnitOfWork the connection recording a client span per statement, children of the span in ctx
+func UnitOfWork(ctx context.Context, uow wrapper.UnitOfWork) wrapper.UnitOfWork {
+	return &tracedUnitOfWork{ctx: ctx, delegate: uow}
+}
+
+// Database replaces the connection stored by service.Database or service.DatabaseWithTx under
+// key with one recording the statements, chain it after them
+func Database(key constants.DatabaseContextValue) service.Middleware {
+	return func(next service.Service) service.Service {
+		return func(ctx context.Context) (interface{}, error) {
+			if uow, ok := ctx.Value(key).(wrapper.UnitOfWork); ok {
+				ctx = context.WithValue(ctx, key, UnitOfWork(ctx, uow))
+			}
+			return next(ctx)
+		}
+	}
+}
+
+func (c *tracedCache) Set(key string, value interface{}, ttl time.Duration) error {
+	span := c.start("SET", key)
+	defer span.End()
+
+	err := c.CacheServer.Set(key, value, ttl)
+	span.RecordError(err)
+	return err
+}
+
+func (c *tracedCache) Get(key string, target interface{}) (interface{}, error) {
+	span := c.start("GET", key)
+	defer span.End()
+
+	value, err := c.CacheServer.Get(key, target)
+	span.RecordError(err)
+	return value, err
+}
+
+func (c *tracedCache) Expire(key string, ttl time.Duration) error {
+	span := c.start("EXPIRE", key)
+	defer span.End()
+
+	err := c.CacheServer.Expire(key, ttl)
+	span.RecordError(err)
+	return err
+}
+
+func (c *tracedCache) Delete(key string) error {
+	span := c.start("DEL", key)
+	defer span.End()
+
+	err := c.CacheServer.Delete(key)
+	span.RecordError(err)
+	return err
+}
+
+func (c *tracedCache) DeleteAll(key string) error {
+	span := c.start("DEL *", key)
+	defer span.End()
+
+	err := c.CacheServer.DeleteAll(key)
+	span.RecordError(err)
+	return err
+}
+
+func (c *tracedRedisCache) Ping() error {
+	return c.redis.Ping()
+}
+
+func (c *tracedRedisCache) Eval(script string, keys []string, args ...interface{}) (interface{}, error) {
+	span := c.start("EVAL", strings.Join(keys, " "))
+	defer span.End()
+
+	value, err := c.redis.Eval(script, keys, args...)
+	span.RecordError(err)
+	return value, err
+}
+
+func (c *tracedCache) start(operation, key string) *Span {
+	_, span := Start(c.ctx, "cache "+operation, WithKind(SpanKindClient), WithAttributes(map[string]interface{}{
+		"db.system":    c.system,
+		"db.operation": operation,
+		"cache.key":    key,
+	}))
+	return span
+}
+
+func (u *tracedUnitOfWork) MustNamedExec(query string, arg interface{}) sql.Result {
+	span := u.start(query)
+	defer span.End()
+
+	return u.delegate.MustNamedExec(query, arg)
+}
+
+func (u *tracedUnitOfWork) Query(query string, args ...interface{}) (*sqlx.Rows, error) {
+	span := u.start(query)
+	defer span.End()
+
+	rows, err := u.delegate.Query(query, args...)
+	span.RecordError(err)
+	return rows, err
+}
+
+func (u *tracedUnitOfWork) Select(dest interface{}, query string, args ...interface{}) error {
+	span := u.start(query)
+	defer span.End()
+
+	err := u.delegate.Select(dest, query, args...)
+	span.RecordError(err)
+	return err
+}
+
+func (u *tracedUnitOfWork) NamedQuery(query string, arg interface{}) (*sqlx.Rows, error) {
+	span := u.start(query)
+	defer span.End()
+
+	rows, err := u.delegate.NamedQuery(query, arg)
+	span.RecordError(err)
+	return rows, err
+}
+
+func (u *tracedUnitOfWork) MustExec(query string, args ...interface{}) sql.Result {
+	span := u.start(query)
+	defer span.End()
+
+	return u.delegate.MustExec(query, args...)
+}
+
+func (u *tracedUnitOfWork) Get(dest interface{}, query string, args ...interface{}) error {
+	span := u.start(query)
+	defer span.End()
+
+	err := u.delegate.Get(dest, query, args...)
+	span.RecordError(err)
+	return err
+}
+
+func (u *tracedUnitOfWork) InTransaction(contextOver func(db wrapper.UnitOfWork) (interface{}, error)) (interface{}, error) {
+	ctx, span := Start(u.ctx, "db transaction", WithKind(SpanKindClient), WithAttributes(map[string]interface{}{"db.system": "postgresql"}))
+	defer span.End()
+
+	result, err := u.delegate.InTransaction(func(db wrapper.UnitOfWork) (interface{}, error) {
+		return contextOver(UnitOfWork(ctx, db))
+	})
+	span.RecordError(err)
+
+	return result, err
+}
+
+func (u *tracedUnitOfWork) Commit() error {
+	span := u.start("COMMIT")
+	defer span.End()
+
+	err := u.delegate.Commit()
+	span.RecordError(err)
+	return err
+}
+
+func (u *tracedUnitOfWork) Rollback() error {
+	span := u.start("ROLLBACK")
+	defer span.End()
+
+	err := u.delegate.Rollback()
+	span.RecordError(err)
+	return err
+}
+
+func (u *tracedUnitOfWork) start(statement string) *Span {
+	_, span := Start(u.ctx, "db query", WithKind(SpanKindClient), WithAttributes(map[string]interface{}{
+		"db.system":    "postgresql",
+		"db.statement": statement,
+	}))
+	return span
+}
+
+// headerCarrier map of headers as Carrier
+type headerCarrier map[string]string
+
+func (h headerCarrier) Get(key string) string {
+	return h[key]
+}
+
+func (h headerCarrier) Set(key, value string) {
+	h[key] = value
+}
@@ -0,0 +1,102 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/helderfarias/go-api-kit/apierror"
+	"github.com/helderfarias/go-api-kit/endpoint"
+	"github.com/helderfarias/go-api-kit/httptransport"
+	"github.com/helderfarias/go-api-kit/service"
+	"gopkg.in/resty.v1"
+)
+
+// MiddlewareOptions tracing configurations
+type MiddlewareOptions struct {
+	// Tracer defaults to Default()
+	Tracer *Tracer
+	// Kind defaults to SpanKindServer for endpoints and SpanKindInternal for services
+	Kind SpanKind
+}
+
+// Middleware wraps the endpoint in a span named name, child of the trace context extracted by
+// ExtractHTTP (or any span already in the context). Errors and 5xx responses mark the span failed.
+func Middleware(name string, options ...MiddlewareOptions) endpoint.Middleware {
+	opt := middlewareOptions(SpanKindServer, options)
+
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(parent context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+			ctx, span := tracerOf(opt).Start(parent, name, WithKind(opt.Kind))
+			defer span.End()
+
+			if r, ok := httptransport.RequestFromContext(ctx); ok {
+				span.SetAttribute("http.method", r.Method)
+				span.SetAttribute("http.target", r.URL.Path)
+			}
+
+			resp, err := next(ctx, request)
+
+			if err != nil {
+				span.RecordError(err)
+				span.SetAttribute("http.status_code", apierror.From(err).StatusCode())
+			} else if resp != nil {
+				span.SetAttribute("http.status_code", resp.Code())
+				if resp.Code() >= http.StatusInternalServerError {
+					span.SetStatus(StatusError, http.StatusText(resp.Code()))
+				}
+			}
+
+			return resp, err
+		}
+	}
+}
+
+// ServiceMiddleware wraps the service in a span named name
+func ServiceMiddleware(name string, options ...MiddlewareOptions) service.Middleware {
+	opt := middlewareOptions(SpanKindInternal, options)
+
+	return func(next service.Service) service.Service {
+		return func(parent context.Context) (interface{}, error) {
+			ctx, span := tracerOf(opt).Start(parent, name, WithKind(opt.Kind))
+			defer span.End()
+
+			resp, err := next(ctx)
+			span.RecordError(err)
+
+			return resp, err
+		}
+	}
+}
+
+// ExtractHTTP reads traceparent/tracestate of the incoming request, use with httptransport.ServerBefore
+func ExtractHTTP(ctx context.Context, r *http.Request) context.Context {
+	return Extract(ctx, r.Header)
+}
+
+// InjectHTTP writes traceparent/tracestate into the outgoing request, use with httptransport.ClientBefore
+func InjectHTTP(ctx context.Context, r *resty.Request) context.Context {
+	if r.Header == nil {
+		r.Header = http.Header{}
+	}
+
+	Inject(ctx, r.Header)
+	return ctx
+}
+
+func middlewareOptions(kind SpanKind, options []MiddlewareOptions) MiddlewareOptions {
+	opt := MiddlewareOptions{Kind: kind}
+	if len(options) >= 1 {
+		opt = options[0]
+		if opt.Kind == 0 {
+			opt.Kind = kind
+		}
+	}
+	return opt
+}
+
+func tracerOf(opt MiddlewareOptions) *Tracer {
+	if opt.Tracer != nil {
+		return opt.Tracer
+	}
+	return Default()
+}
@@ -0,0 +1,88 @@
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// TraceparentHeader W3C trace-context header, e.g. 00-<trace id>-<span id>-01
+	TraceparentHeader = "traceparent"
+	// TracestateHeader vendor specific trace data, propagated untouched
+	TracestateHeader = "tracestate"
+)
+
+// ErrInvalidTraceparent the traceparent header is malformed
+var ErrInvalidTraceparent = errors.New("invalid traceparent")
+
+// Carrier headers carrying the trace context, implemented by http.Header and nats.Header
+type Carrier interface {
+	Get(key string) string
+	Set(key, value string)
+}
+
+// Traceparent the W3C traceparent value of the span context
+func (sc SpanContext) Traceparent() string {
+	return fmt.Sprintf("00-%v-%v-%02x", sc.TraceID, sc.SpanID, sc.Flags)
+}
+
+// ParseTraceparent parses the W3C traceparent value, versions other than 00 are read as 00
+func ParseTraceparent(value string) (SpanContext, error) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || (parts[0] == "00" && len(parts) != 4) {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+
+	sc := SpanContext{}
+
+	if len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+
+	if _, err := hex.Decode(sc.TraceID[:], []byte(parts[1])); err != nil {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+
+	if _, err := hex.Decode(sc.SpanID[:], []byte(parts[2])); err != nil {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+
+	flags := make([]byte, 1)
+	if _, err := hex.Decode(flags, []byte(parts[3])); err != nil {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+	sc.Flags = flags[0]
+
+	if !sc.IsValid() || strings.ToLower(value) != value {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+
+	return sc, nil
+}
+
+// Inject writes the current span context into the carrier, nothing is written without one
+func Inject(ctx context.Context, carrier Carrier) {
+	sc := SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return
+	}
+
+	carrier.Set(TraceparentHeader, sc.Traceparent())
+	if sc.TraceState != "" {
+		carrier.Set(TracestateHeader, sc.TraceState)
+	}
+}
+
+// Extract reads the span context of the carrier as the remote parent, invalid values are ignored
+func Extract(ctx context.Context, carrier Carrier) context.Context {
+	sc, err := ParseTraceparent(carrier.Get(TraceparentHeader))
+	if err != nil {
+		return ctx
+	}
+
+	sc.TraceState = carrier.Get(TracestateHeader)
+	return ContextWithRemoteSpanContext(ctx, sc)
+}
@@ -0,0 +1,233 @@
+package tracing
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// TraceID 16 bytes identifying the trace
+type TraceID [16]byte
+
+// SpanID 8 bytes identifying the span
+type SpanID [8]byte
+
+// SpanKind role of the span, same values as OpenTelemetry
+type SpanKind int
+
+const (
+	SpanKindInternal SpanKind = iota + 1
+	SpanKindServer
+	SpanKindClient
+	SpanKindProducer
+	SpanKindConsumer
+)
+
+// StatusCode outcome of the span, same values as OpenTelemetry
+type StatusCode int
+
+const (
+	StatusUnset StatusCode = iota
+	StatusOK
+	StatusError
+)
+
+// FlagSampled trace flag of sampled spans
+const FlagSampled byte = 0x01
+
+// SpanContext the propagated part of a span
+type SpanContext struct {
+	TraceID    TraceID
+	SpanID     SpanID
+	Flags      byte
+	TraceState string
+	Remote     bool
+}
+
+// Status outcome of the span
+type Status struct {
+	Code    StatusCode `json:"code"`
+	Message string     `json:"message,omitempty"`
+}
+
+// Event timestamped annotation of a span
+type Event struct {
+	Name       string                 `json:"name"`
+	Time       time.Time              `json:"time"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+}
+
+// SpanData ended span handed to the exporter
+type SpanData struct {
+	Service      string                 `json:"service,omitempty"`
+	Name         string                 `json:"name"`
+	Kind         SpanKind               `json:"kind"`
+	TraceID      TraceID                `json:"traceId"`
+	SpanID       SpanID                 `json:"spanId"`
+	ParentSpanID SpanID                 `json:"parentSpanId"`
+	TraceState   string                 `json:"traceState,omitempty"`
+	Start        time.Time              `json:"start"`
+	End          time.Time              `json:"end"`
+	Attributes   map[string]interface{} `json:"attributes,omitempty"`
+	Events       []Event                `json:"events,omitempty"`
+	Status       Status                 `json:"status"`
+}
+
+// Span a timed operation, methods of a nil span are no-ops so instrumented code works without a
+// span in the context
+type Span struct {
+	tracer *Tracer
+	sc     SpanContext
+
+	mu    sync.Mutex
+	data  SpanData
+	ended bool
+}
+
+// String lowercase hex
+func (t TraceID) String() string {
+	return hex.EncodeToString(t[:])
+}
+
+// IsValid false for the all zeros id
+func (t TraceID) IsValid() bool {
+	return t != TraceID{}
+}
+
+// MarshalText hex, used by the JSON exporters
+func (t TraceID) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// String lowercase hex
+func (s SpanID) String() string {
+	return hex.EncodeToString(s[:])
+}
+
+// IsValid false for the all zeros id
+func (s SpanID) IsValid() bool {
+	return s != SpanID{}
+}
+
+// MarshalText hex, used by the JSON exporters
+func (s SpanID) MarshalText() ([]byte, error) {
+	if !s.IsValid() {
+		return []byte{}, nil
+	}
+	return []byte(s.String()), nil
+}
+
+// IsValid both ids are set
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// IsSampled the span is exported
+func (sc SpanContext) IsSampled() bool {
+	return sc.Flags&FlagSampled == FlagSampled
+}
+
+// Context the propagated part of the span
+func (s *Span) Context() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.sc
+}
+
+// SetAttribute annotates the span, e.g. SetAttribute("db.statement", query)
+func (s *Span) SetAttribute(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ended {
+		return
+	}
+
+	if s.data.Attributes == nil {
+		s.data.Attributes = map[string]interface{}{}
+	}
+	s.data.Attributes[key] = value
+}
+
+// AddEvent records an event at the current time
+func (s *Span) AddEvent(name string, attributes map[string]interface{}) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ended {
+		return
+	}
+	s.data.Events = append(s.data.Events, Event{Name: name, Time: time.Now(), Attributes: attributes})
+}
+
+// RecordError adds an exception event and sets the status to error, nil errors are ignored
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+
+	s.AddEvent("exception", map[string]interface{}{
+		"exception.type":    fmt.Sprintf("%T", err),
+		"exception.message": err.Error(),
+	})
+	s.SetStatus(StatusError, err.Error())
+}
+
+// SetStatus sets the outcome
+func (s *Span) SetStatus(code StatusCode, message string) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ended {
+		return
+	}
+	s.data.Status = Status{Code: code, Message: message}
+}
+
+// End finishes the span and hands it to the exporter when sampled, further calls are ignored
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.End = time.Now()
+	data := s.data
+	s.mu.Unlock()
+
+	if s.sc.IsSampled() && s.tracer != nil {
+		s.tracer.enqueue(data)
+	}
+}
+
+func newTraceID() TraceID {
+	var id TraceID
+	rand.Read(id[:])
+	return id
+}
+
+func newSpanID() SpanID {
+	var id SpanID
+	rand.Read(id[:])
+	return id
+}
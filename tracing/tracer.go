@@ -0,0 +1,333 @@
+// Package tracing spans following the OpenTelemetry concepts, propagated with the W3C
+// trace-context headers (traceparent/tracestate) through HTTP and NATS, e.g.
+//
+//	tracing.SetDefault(tracing.NewTracer("orders", tracing.WithExporter(tracing.NewOTLPExporter(url))))
+//
+//	httptransport.NewServer(tracing.Middleware("orders.get")(e), dec, enc, httptransport.ServerBefore(tracing.ExtractHTTP))
+package tracing
+
+import (
+	"context"
+	"encoding/binary"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type contextKey string
+
+const (
+	spanContextKey   contextKey = "tracing.span"
+	remoteContextKey contextKey = "tracing.remote"
+)
+
+// Tracer creates spans and exports the sampled ones in batches
+type Tracer struct {
+	service       string
+	exporter      Exporter
+	ratio         float64
+	batchSize     int
+	maxQueue      int
+	flushInterval time.Duration
+
+	mu      sync.Mutex
+	pending []SpanData
+	started bool
+	closed  bool
+	flush   chan chan struct{}
+	done    chan struct{}
+	stopped chan struct{}
+	once    sync.Once
+}
+
+// TracerOption sets an optional parameter for tracers
+type TracerOption func(t *Tracer)
+
+// SpanOption sets an optional parameter for a span
+type SpanOption func(s *Span)
+
+var (
+	defaultMu     sync.RWMutex
+	defaultTracer = NewTracer("")
+)
+
+// NewTracer constructs a tracer sampling every trace, spans are only propagated until an
+// exporter is set
+func NewTracer(service string, options ...TracerOption) *Tracer {
+	t := &Tracer{
+		service:       service,
+		ratio:         1,
+		batchSize:     512,
+		maxQueue:      2048,
+		flushInterval: 5 * time.Second,
+		flush:         make(chan chan struct{}),
+		done:          make(chan struct{}),
+		stopped:       make(chan struct{}),
+	}
+
+	for _, o := range options {
+		o(t)
+	}
+
+	return t
+}
+
+// WithExporter exporter of the ended spans
+func WithExporter(e Exporter) TracerOption {
+	return func(t *Tracer) { t.exporter = e }
+}
+
+// WithSampleRatio fraction of the new traces sampled, child spans follow the parent decision
+func WithSampleRatio(ratio float64) TracerOption {
+	return func(t *Tracer) { t.ratio = ratio }
+}
+
+// WithBatch spans exported at once and the max time they wait
+func WithBatch(size int, interval time.Duration) TracerOption {
+	return func(t *Tracer) {
+		t.batchSize = size
+		t.flushInterval = interval
+	}
+}
+
+// WithMaxQueue spans waiting for the export, the ended spans are dropped while the queue
+// is full, defaults to 2048
+func WithMaxQueue(size int) TracerOption {
+	return func(t *Tracer) { t.maxQueue = size }
+}
+
+// WithKind role of the span, defaults to SpanKindInternal
+func WithKind(kind SpanKind) SpanOption {
+	return func(s *Span) { s.data.Kind = kind }
+}
+
+// WithAttributes initial attributes of the span
+func WithAttributes(attributes map[string]interface{}) SpanOption {
+	return func(s *Span) {
+		for k, v := range attributes {
+			if s.data.Attributes == nil {
+				s.data.Attributes = map[string]interface{}{}
+			}
+			s.data.Attributes[k] = v
+		}
+	}
+}
+
+// SetDefault replaces the tracer used by the package functions and the instrumentation
+func SetDefault(t *Tracer) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultTracer = t
+}
+
+// Default the tracer used by the package functions and the instrumentation
+func Default() *Tracer {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultTracer
+}
+
+// Start starts a span with the default tracer
+func Start(ctx context.Context, name string, options ...SpanOption) (context.Context, *Span) {
+	return Default().Start(ctx, name, options...)
+}
+
+// SpanFromContext the current span, nil when there is none
+func SpanFromContext(ctx context.Context) *Span {
+	if ctx == nil {
+		return nil
+	}
+
+	span, _ := ctx.Value(spanContextKey).(*Span)
+	return span
+}
+
+// ContextWithRemoteSpanContext sets the parent received from another service, used by Extract
+func ContextWithRemoteSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	sc.Remote = true
+	return context.WithValue(ctx, remoteContextKey, sc)
+}
+
+// SpanContextFromContext the current span context, local or remote
+func SpanContextFromContext(ctx context.Context) SpanContext {
+	if span := SpanFromContext(ctx); span != nil {
+		return span.Context()
+	}
+
+	if ctx != nil {
+		if sc, ok := ctx.Value(remoteContextKey).(SpanContext); ok {
+			return sc
+		}
+	}
+
+	return SpanContext{}
+}
+
+// Start starts a child of the span in ctx (local or remote), or a new trace
+func (t *Tracer) Start(ctx context.Context, name string, options ...SpanOption) (context.Context, *Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	parent := SpanContextFromContext(ctx)
+
+	span := &Span{tracer: t}
+	span.data.Name = name
+	span.data.Service = t.service
+	span.data.Kind = SpanKindInternal
+	span.data.Start = time.Now()
+
+	if parent.IsValid() {
+		span.sc = SpanContext{TraceID: parent.TraceID, Flags: parent.Flags, TraceState: parent.TraceState}
+		span.data.ParentSpanID = parent.SpanID
+	} else {
+		span.sc = SpanContext{TraceID: newTraceID()}
+		if t.sample(span.sc.TraceID) {
+			span.sc.Flags = FlagSampled
+		}
+	}
+
+	span.sc.SpanID = newSpanID()
+	span.data.TraceID = span.sc.TraceID
+	span.data.SpanID = span.sc.SpanID
+	span.data.TraceState = span.sc.TraceState
+
+	for _, o := range options {
+		o(span)
+	}
+
+	return context.WithValue(ctx, spanContextKey, span), span
+}
+
+// Flush exports the pending spans
+func (t *Tracer) Flush(ctx context.Context) {
+	t.mu.Lock()
+	started := t.started
+	t.mu.Unlock()
+
+	if !started {
+		return
+	}
+
+	ack := make(chan struct{})
+	select {
+	case t.flush <- ack:
+	case <-t.done:
+		return
+	case <-ctx.Done():
+		return
+	}
+
+	select {
+	case <-ack:
+	case <-ctx.Done():
+	}
+}
+
+// Shutdown exports the pending spans and shuts the exporter down, spans ended afterwards
+// are dropped
+func (t *Tracer) Shutdown(ctx context.Context) error {
+	t.mu.Lock()
+	t.closed = true
+	started := t.started
+	t.mu.Unlock()
+
+	t.once.Do(func() { close(t.done) })
+
+	if started {
+		select {
+		case <-t.stopped:
+		case <-ctx.Done():
+		}
+	}
+
+	if t.exporter == nil {
+		return nil
+	}
+
+	return t.exporter.Shutdown(ctx)
+}
+
+// sample keeps the traces whose id falls in the ratio, so every service of a trace agrees
+func (t *Tracer) sample(id TraceID) bool {
+	if t.ratio >= 1 {
+		return true
+	}
+
+	if t.ratio <= 0 {
+		return false
+	}
+
+	return float64(binary.BigEndian.Uint64(id[8:])>>1) < t.ratio*float64(uint64(1)<<63)
+}
+
+func (t *Tracer) enqueue(span SpanData) {
+	if t.exporter == nil {
+		return
+	}
+
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		return
+	}
+	if len(t.pending) >= t.maxQueue {
+		t.mu.Unlock()
+		logrus.WithField("tracing.dropped", span.Name).Debug("tracing: queue full")
+		return
+	}
+	t.pending = append(t.pending, span)
+	full := len(t.pending) >= t.batchSize
+	if !t.started {
+		t.started = true
+		go t.loop()
+	}
+	t.mu.Unlock()
+
+	if full {
+		select {
+		case t.flush <- nil:
+		default:
+		}
+	}
+}
+
+func (t *Tracer) loop() {
+	ticker := time.NewTicker(t.flushInterval)
+	defer ticker.Stop()
+	defer close(t.stopped)
+
+	for {
+		select {
+		case ack := <-t.flush:
+			t.export()
+			if ack != nil {
+				close(ack)
+			}
+		case <-ticker.C:
+			t.export()
+		case <-t.done:
+			t.export()
+			return
+		}
+	}
+}
+
+func (t *Tracer) export() {
+	t.mu.Lock()
+	spans := t.pending
+	t.pending = nil
+	t.mu.Unlock()
+
+	if len(spans) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := t.exporter.Export(ctx, spans); err != nil {
+		logrus.Warnf("tracing: exporting %v spans: %v", len(spans), err)
+	}
+}
@@ -0,0 +1,302 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/helderfarias/go-api-kit/cache"
+	"github.com/helderfarias/go-api-kit/endpoint"
+	"github.com/helderfarias/go-api-kit/httptransport"
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/resty.v1"
+)
+
+type memoryExporter struct {
+	mu    sync.Mutex
+	spans []SpanData
+	// exported spans when the exporter was shut down
+	exportedAtShutdown int
+}
+
+func (e *memoryExporter) Export(ctx context.Context, spans []SpanData) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = append(e.spans, spans...)
+	return nil
+}
+
+func (e *memoryExporter) Shutdown(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.exportedAtShutdown = len(e.spans)
+	return nil
+}
+
+func (e *memoryExporter) byName(name string) SpanData {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, span := range e.spans {
+		if span.Name == name {
+			return span
+		}
+	}
+	return SpanData{}
+}
+
+// withTracer sets a default tracer exporting to memory, restore with defer SetDefault(Default())
+func withTracer(options ...TracerOption) (*Tracer, *memoryExporter) {
+	exporter := &memoryExporter{}
+	tracer := NewTracer("orders", append([]TracerOption{WithExporter(exporter)}, options...)...)
+	SetDefault(tracer)
+
+	return tracer, exporter
+}
+
+func TestTraceparent(t *testing.T) {
+	sc, err := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceID.String())
+	assert.Equal(t, "00f067aa0ba902b7", sc.SpanID.String())
+	assert.True(t, sc.IsSampled())
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", sc.Traceparent())
+
+	for _, invalid := range []string{
+		"",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+	} {
+		_, err := ParseTraceparent(invalid)
+		assert.Equal(t, ErrInvalidTraceparent, err, invalid)
+	}
+
+	_, err = ParseTraceparent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra")
+	assert.Nil(t, err)
+}
+
+func TestPropagationAcrossHTTP(t *testing.T) {
+	defer SetDefault(Default())
+	tracer, exporter := withTracer()
+
+	server := httptest.NewServer(httptransport.NewServer(
+		Middleware("orders.get")(func(ctx context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+			return nil, errors.New("boom")
+		}),
+		httptransport.NopRequestDecoder,
+		httptransport.EncodeJSONResponse,
+		httptransport.ServerBefore(ExtractHTTP),
+	))
+	defer server.Close()
+
+	ctx, client := Start(context.Background(), "checkout", WithKind(SpanKindClient))
+	req := resty.New().R()
+	InjectHTTP(ctx, req)
+	req.Header.Set(TracestateHeader, "vendor=1")
+	_, err := req.Get(server.URL + "/orders/1")
+	client.End()
+
+	assert.Nil(t, err)
+
+	tracer.Flush(context.Background())
+
+	span := exporter.byName("orders.get")
+	assert.Equal(t, client.Context().TraceID, span.TraceID)
+	assert.Equal(t, client.Context().SpanID, span.ParentSpanID)
+	assert.Equal(t, "vendor=1", span.TraceState)
+	assert.Equal(t, SpanKindServer, span.Kind)
+	assert.Equal(t, "orders", span.Service)
+	assert.Equal(t, "GET", span.Attributes["http.method"])
+	assert.Equal(t, 500, span.Attributes["http.status_code"])
+	assert.Equal(t, StatusError, span.Status.Code)
+	assert.Equal(t, "exception", span.Events[0].Name)
+}
+
+func TestPropagationAcrossNATS(t *testing.T) {
+	defer SetDefault(Default())
+	tracer, exporter := withTracer()
+
+	ctx, producer := Start(context.Background(), "publish orders.created", WithKind(SpanKindProducer))
+	msg := nats.NewMsg("orders.created")
+	Inject(ctx, msg.Header)
+	producer.End()
+
+	var current SpanContext
+	Consumer("orders.created", func(ctx context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+		current = SpanContextFromContext(ctx)
+		return nil, nil
+	})(context.Background(), msg)
+
+	tracer.Flush(context.Background())
+
+	span := exporter.byName("consume orders.created")
+	assert.Equal(t, producer.Context().TraceID, span.TraceID)
+	assert.Equal(t, producer.Context().SpanID, span.ParentSpanID)
+	assert.Equal(t, span.SpanID, current.SpanID)
+	assert.Equal(t, "nats", span.Attributes["messaging.system"])
+}
+
+func TestJobAndCacheSpans(t *testing.T) {
+	defer SetDefault(Default())
+	tracer, exporter := withTracer()
+
+	job := Job("cleanup", func() {
+		panic("boom")
+	})
+	assert.Panics(t, func() { job() })
+
+	ctx, parent := Start(context.Background(), "orders.list")
+	c := Cache(ctx, cache.NewCacheServer())
+	c.Set("orders", []int{1}, time.Minute)
+	parent.End()
+
+	tracer.Flush(context.Background())
+
+	cron := exporter.byName("cron cleanup")
+	assert.False(t, cron.ParentSpanID.IsValid())
+	assert.Equal(t, "panic: boom", cron.Status.Message)
+
+	set := exporter.byName("cache SET")
+	assert.Equal(t, parent.Context().SpanID, set.ParentSpanID)
+	assert.Equal(t, "memory", set.Attributes["db.system"])
+	assert.Equal(t, "orders", set.Attributes["cache.key"])
+}
+
+type redisCacheMock struct {
+	cache.CacheServer
+}
+
+func (redisCacheMock) Ping() error {
+	return nil
+}
+
+func (redisCacheMock) Eval(script string, keys []string, args ...interface{}) (interface{}, error) {
+	return int64(1), nil
+}
+
+func TestCacheKeepsPingerAndScriptRunner(t *testing.T) {
+	defer SetDefault(Default())
+	tracer, exporter := withTracer()
+
+	c := Cache(context.Background(), redisCacheMock{cache.NewCacheServer()})
+
+	_, isPinger := c.(cache.Pinger)
+	assert.True(t, isPinger)
+
+	runner, ok := c.(cache.ScriptRunner)
+	assert.True(t, ok)
+	value, err := runner.Eval("return 1", []string{"quota:orders"})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), value)
+
+	_, isPinger = Cache(context.Background(), cache.NewCacheServer()).(cache.Pinger)
+	assert.False(t, isPinger)
+
+	tracer.Flush(context.Background())
+
+	eval := exporter.byName("cache EVAL")
+	assert.Equal(t, "redis", eval.Attributes["db.system"])
+	assert.Equal(t, "quota:orders", eval.Attributes["cache.key"])
+}
+
+func TestNotSampledSpansPropagateWithoutExport(t *testing.T) {
+	defer SetDefault(Default())
+	tracer, exporter := withTracer(WithSampleRatio(0))
+
+	ctx, span := Start(context.Background(), "ignored")
+	header := http.Header{}
+	Inject(ctx, header)
+	span.End()
+
+	tracer.Flush(context.Background())
+
+	assert.Empty(t, exporter.spans)
+	assert.Regexp(t, "^00-[0-9a-f]{32}-[0-9a-f]{16}-00$", header.Get(TraceparentHeader))
+}
+
+func TestShutdownExportsBeforeTheExporterShutsDown(t *testing.T) {
+	exporter := &memoryExporter{}
+	tracer := NewTracer("orders", WithExporter(exporter), WithBatch(100, time.Hour))
+
+	_, span := tracer.Start(context.Background(), "orders.create")
+	span.End()
+
+	assert.Nil(t, tracer.Shutdown(context.Background()))
+
+	_, late := tracer.Start(context.Background(), "orders.late")
+	late.End()
+
+	assert.Equal(t, 1, exporter.exportedAtShutdown)
+	assert.Len(t, exporter.spans, 1)
+	assert.Empty(t, tracer.pending)
+}
+
+func TestQueueDropsSpansWhenFull(t *testing.T) {
+	exporter := &memoryExporter{}
+	tracer := NewTracer("orders", WithExporter(exporter), WithBatch(100, time.Hour), WithMaxQueue(2))
+
+	for i := 0; i < 5; i++ {
+		_, span := tracer.Start(context.Background(), "orders.list")
+		span.End()
+	}
+
+	tracer.mu.Lock()
+	queued := len(tracer.pending)
+	tracer.mu.Unlock()
+	tracer.Flush(context.Background())
+
+	assert.Equal(t, 2, queued)
+	assert.Len(t, exporter.spans, 2)
+}
+
+func TestOTLPExporter(t *testing.T) {
+	body := make(chan map[string]interface{}, 1)
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		payload := map[string]interface{}{}
+		json.Unmarshal(data, &payload)
+		body <- payload
+	}))
+	defer collector.Close()
+
+	tracer := NewTracer("orders", WithExporter(NewOTLPExporter(collector.URL+"/v1/traces")))
+	_, span := tracer.Start(context.Background(), "orders.get", WithAttributes(map[string]interface{}{"order.id": 10}))
+	span.End()
+
+	assert.Nil(t, tracer.Shutdown(context.Background()))
+
+	payload := <-body
+	resource := payload["resourceSpans"].([]interface{})[0].(map[string]interface{})
+	attributes := resource["resource"].(map[string]interface{})["attributes"].([]interface{})
+	assert.Equal(t, map[string]interface{}{"key": "service.name", "value": map[string]interface{}{"stringValue": "orders"}}, attributes[0])
+
+	exported := resource["scopeSpans"].([]interface{})[0].(map[string]interface{})["spans"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, span.Context().TraceID.String(), exported["traceId"])
+	assert.Equal(t, "orders.get", exported["name"])
+	assert.Equal(t, float64(SpanKindInternal), exported["kind"])
+	assert.Nil(t, exported["parentSpanId"])
+}
+
+func TestStdoutExporter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	tracer := NewTracer("orders", WithExporter(NewStdoutExporter(buf)))
+
+	_, span := tracer.Start(context.Background(), "orders.get")
+	span.End()
+	tracer.Shutdown(context.Background())
+
+	assert.Contains(t, buf.String(), `"name":"orders.get"`)
+	assert.Contains(t, buf.String(), `"traceId":"`+span.Context().TraceID.String()+`"`)
+}
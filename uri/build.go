@@ -3,6 +3,7 @@ package uri
 import (
 	"log"
 	"net/url"
+	"strings"
 )
 
 type URI struct {
@@ -40,3 +41,8 @@ func (u *URI) String() string {
 	}
 	return base.String()
 }
+
+// Template the URI keeping path params like {id} unescaped
+func (u *URI) Template() string {
+	return strings.NewReplacer("%7B", "{", "%7D", "}").Replace(u.String())
+}
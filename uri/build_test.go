@@ -86,6 +86,12 @@ func TestBuildUriCustom(t *testing.T) {
 	assert.Equal(t, "/v1/1?n=1", r)
 }
 
+func TestBuildUriTemplate(t *testing.T) {
+	b := NewBuildURI("http://localhost").Path("v1/users/{id}").QueryParam("q", "a b")
+
+	assert.Equal(t, "http://localhost/v1/users/{id}?q=a+b", b.Template())
+}
+
 func service() *URIPath {
 	return NewPaths()
 }
@@ -0,0 +1,358 @@
+package validation
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Func validates the value against the rule param (e.g. "3" for min=3)
+type Func func(value reflect.Value, param string) bool
+
+// FieldError a failed rule
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+// Errors all the failed rules of a request
+type Errors []FieldError
+
+// Validator validates structs using the `validate` tag, e.g.
+//
+//	type Address struct {
+//		Street string   `json:"street" validate:"required,max=120"`
+//		Kind   string   `json:"kind" validate:"oneof=home work"`
+//		Tags   []string `json:"tags" validate:"max=5,dive,min=2"`
+//	}
+//
+// Rules are separated by comma, nested structs are always validated and
+// "dive" applies the following rules to each element of a slice or map.
+// The regex rule takes the rest of the tag, so it must be the last one.
+type Validator struct {
+	mu    sync.RWMutex
+	rules map[string]Func
+	regex sync.Map
+}
+
+var emailRegex = regexp.MustCompile(`^[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}$`)
+
+// Default validator used by the package functions
+var Default = New()
+
+// New validator with the builtin rules
+func New() *Validator {
+	v := &Validator{rules: map[string]Func{}}
+
+	v.Register("required", required)
+	v.Register("min", func(value reflect.Value, param string) bool {
+		return compare(value, param, func(a, b float64) bool { return a >= b })
+	})
+	v.Register("max", func(value reflect.Value, param string) bool {
+		return compare(value, param, func(a, b float64) bool { return a <= b })
+	})
+	v.Register("len", func(value reflect.Value, param string) bool {
+		return compare(value, param, func(a, b float64) bool { return a == b })
+	})
+	v.Register("email", func(value reflect.Value, param string) bool {
+		return isEmpty(value) || emailRegex.MatchString(fmt.Sprint(indirect(value).Interface()))
+	})
+	v.Register("oneof", oneof)
+	v.Register("regex", v.matches)
+
+	return v
+}
+
+// Register adds or replaces a rule of the default validator
+func Register(name string, fn Func) {
+	Default.Register(name, fn)
+}
+
+// Validate validates the struct with the default validator
+func Validate(s interface{}) error {
+	return Default.Validate(s)
+}
+
+// Register adds or replaces a rule
+func (v *Validator) Register(name string, fn Func) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.rules[name] = fn
+}
+
+// Validate returns Errors when any rule fails, requests that aren't structs are ignored
+func (v *Validator) Validate(s interface{}) error {
+	errs := Errors{}
+
+	value := reflect.ValueOf(s)
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+
+	if err := v.validateStruct("", value, &errs); err != nil {
+		return err
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+func (e Errors) Error() string {
+	messages := []string{}
+	for _, f := range e {
+		messages = append(messages, f.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+// SplitRules splits the validate tag by comma, except the pattern of the regex rule which
+// takes the rest of the tag, e.g. "required,regex=^\d{1,3}$"
+func SplitRules(tag string) []string {
+	rules := []string{}
+
+	for tag != "" {
+		tag = strings.TrimLeft(tag, " ")
+		if strings.HasPrefix(tag, "regex=") {
+			return append(rules, tag)
+		}
+
+		rule := tag
+		if idx := strings.Index(tag, ","); idx >= 0 {
+			rule, tag = tag[:idx], tag[idx+1:]
+		} else {
+			tag = ""
+		}
+
+		rules = append(rules, strings.TrimSpace(rule))
+	}
+
+	return rules
+}
+
+func (v *Validator) validateStruct(path string, value reflect.Value, errs *Errors) error {
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+
+	kind := value.Type()
+	for i := 0; i < kind.NumField(); i++ {
+		field := kind.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name := fieldName(field)
+		if path != "" {
+			name = path + "." + name
+		}
+
+		rules := SplitRules(field.Tag.Get("validate"))
+
+		if err := v.validateField(name, value.Field(i), rules, errs); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (v *Validator) validateField(name string, value reflect.Value, rules []string, errs *Errors) error {
+	for i, rule := range rules {
+		if rule == "" || rule == "-" {
+			continue
+		}
+
+		if rule == "dive" {
+			return v.dive(name, value, rules[i+1:], errs)
+		}
+
+		param := ""
+		if idx := strings.Index(rule, "="); idx >= 0 {
+			rule, param = rule[:idx], rule[idx+1:]
+		}
+
+		v.mu.RLock()
+		fn, ok := v.rules[rule]
+		v.mu.RUnlock()
+
+		if !ok {
+			return fmt.Errorf("validation: unknown rule %q on field %v", rule, name)
+		}
+
+		if rule == "regex" {
+			if _, err := v.compile(param); err != nil {
+				return fmt.Errorf("validation: invalid regex %q on field %v: %v", param, name, err)
+			}
+		}
+
+		if !fn(value, param) {
+			*errs = append(*errs, FieldError{Field: name, Rule: rule, Param: param, Message: message(name, rule, param)})
+			if rule == "required" {
+				return nil
+			}
+		}
+	}
+
+	return v.validateStruct(name, indirect(value), errs)
+}
+
+func (v *Validator) dive(name string, value reflect.Value, rules []string, errs *Errors) error {
+	value = indirect(value)
+
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			if err := v.validateField(fmt.Sprintf("%v[%d]", name, i), value.Index(i), rules, errs); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		for _, key := range value.MapKeys() {
+			if err := v.validateField(fmt.Sprintf("%v[%v]", name, key.Interface()), value.MapIndex(key), rules, errs); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (v *Validator) matches(value reflect.Value, param string) bool {
+	if isEmpty(value) {
+		return true
+	}
+
+	re, err := v.compile(param)
+	if err != nil {
+		return false
+	}
+
+	return re.MatchString(fmt.Sprint(indirect(value).Interface()))
+}
+
+// compile compiles the pattern once, invalid patterns are reported by validateField
+func (v *Validator) compile(pattern string) (*regexp.Regexp, error) {
+	if cached, ok := v.regex.Load(pattern); ok {
+		return cached.(*regexp.Regexp), nil
+	}
+
+	compiled, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	cached, _ := v.regex.LoadOrStore(pattern, compiled)
+	return cached.(*regexp.Regexp), nil
+}
+
+func required(value reflect.Value, param string) bool {
+	return !isEmpty(value)
+}
+
+func oneof(value reflect.Value, param string) bool {
+	if isEmpty(value) {
+		return true
+	}
+
+	current := fmt.Sprint(indirect(value).Interface())
+	for _, option := range strings.Fields(param) {
+		if option == current {
+			return true
+		}
+	}
+
+	return false
+}
+
+func compare(value reflect.Value, param string, cmp func(a, b float64) bool) bool {
+	limit, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return false
+	}
+
+	value = indirect(value)
+
+	switch value.Kind() {
+	case reflect.String:
+		return cmp(float64(len([]rune(value.String()))), limit)
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return cmp(float64(value.Len()), limit)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return cmp(float64(value.Int()), limit)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return cmp(float64(value.Uint()), limit)
+	case reflect.Float32, reflect.Float64:
+		return cmp(value.Float(), limit)
+	case reflect.Invalid:
+		return true
+	}
+
+	return false
+}
+
+func isEmpty(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Invalid:
+		return true
+	case reflect.Ptr, reflect.Interface:
+		return value.IsNil()
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return value.Len() == 0
+	case reflect.Chan, reflect.Func:
+		return value.IsNil()
+	case reflect.Struct:
+		return false
+	}
+
+	return value.Interface() == reflect.Zero(value.Type()).Interface()
+}
+
+func indirect(value reflect.Value) reflect.Value {
+	for (value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface) && !value.IsNil() {
+		value = value.Elem()
+	}
+	if value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		return reflect.Value{}
+	}
+	return value
+}
+
+func fieldName(field reflect.StructField) string {
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if name == "" || name == "-" {
+		return field.Name
+	}
+	return name
+}
+
+func message(name, rule, param string) string {
+	switch rule {
+	case "required":
+		return fmt.Sprintf("%v is required", name)
+	case "min":
+		return fmt.Sprintf("%v must be at least %v", name, param)
+	case "max":
+		return fmt.Sprintf("%v must be at most %v", name, param)
+	case "len":
+		return fmt.Sprintf("%v must have length %v", name, param)
+	case "email":
+		return fmt.Sprintf("%v must be a valid email", name)
+	case "oneof":
+		return fmt.Sprintf("%v must be one of [%v]", name, param)
+	case "regex":
+		return fmt.Sprintf("%v must match %v", name, param)
+	}
+
+	return fmt.Sprintf("%v failed on rule %v", name, rule)
+}
@@ -0,0 +1,94 @@
+package validation
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type item struct {
+	Name string `json:"name" validate:"required"`
+}
+
+type address struct {
+	Street  string   `json:"street" validate:"required,max=10"`
+	Zip     string   `json:"zip" validate:"len=5,regex=^[0-9]+$"`
+	Email   string   `json:"email" validate:"email"`
+	Kind    string   `json:"kind" validate:"oneof=home work"`
+	Number  int      `json:"number" validate:"min=1"`
+	Tags    []string `json:"tags" validate:"max=2,dive,min=2"`
+	Items   []item   `json:"items" validate:"dive"`
+	Country *item    `json:"country"`
+}
+
+func TestValidateValidStruct(t *testing.T) {
+	err := Validate(&address{Street: "Main", Zip: "12345", Email: "a@b.com", Kind: "home", Number: 1})
+
+	assert.Nil(t, err)
+}
+
+func TestValidateFieldErrors(t *testing.T) {
+	err := Validate(address{
+		Zip:     "12a",
+		Email:   "invalid",
+		Kind:    "other",
+		Tags:    []string{"a", "ok"},
+		Items:   []item{{}},
+		Country: &item{},
+	})
+
+	fields := []string{}
+	for _, f := range err.(Errors) {
+		fields = append(fields, f.Field+":"+f.Rule)
+	}
+
+	assert.Equal(t, []string{
+		"street:required",
+		"zip:len",
+		"zip:regex",
+		"email:email",
+		"kind:oneof",
+		"number:min",
+		"tags[0]:min",
+		"items[0].name:required",
+		"country.name:required",
+	}, fields)
+}
+
+func TestValidateRegexWithComma(t *testing.T) {
+	type request struct {
+		Code string `validate:"required,regex=^\\d{1,3}$"`
+	}
+
+	assert.Equal(t, []string{"required", `regex=^\d{1,3}$`}, SplitRules(`required, regex=^\d{1,3}$`))
+	assert.Nil(t, New().Validate(request{Code: "123"}))
+	assert.EqualError(t, New().Validate(request{Code: "1234"}), `Code must match ^\d{1,3}$`)
+}
+
+func TestValidateInvalidRegexIsReported(t *testing.T) {
+	type request struct {
+		Code string `validate:"regex=^[0-9$"`
+	}
+
+	err := New().Validate(request{})
+	_, fieldErrors := err.(Errors)
+
+	assert.False(t, fieldErrors)
+	assert.EqualError(t, err, "validation: invalid regex \"^[0-9$\" on field Code: error parsing regexp: missing closing ]: `[0-9$`")
+}
+
+func TestValidateCustomRule(t *testing.T) {
+	v := New()
+	v.Register("upper", func(value reflect.Value, param string) bool {
+		return strings.ToUpper(value.String()) == value.String()
+	})
+
+	type request struct {
+		Code string `validate:"upper"`
+	}
+
+	assert.Nil(t, v.Validate(request{Code: "ABC"}))
+	assert.EqualError(t, v.Validate(request{Code: "abc"}), "Code failed on rule upper")
+}
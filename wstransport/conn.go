@@ -0,0 +1,161 @@
+package wstransport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrConnClosed the connection is closed
+	ErrConnClosed = errors.New("websocket connection closed")
+	// ErrSendBufferFull the client isn't reading fast enough
+	ErrSendBufferFull = errors.New("websocket send buffer full")
+	// ErrConnNotFound no connection with the id
+	ErrConnNotFound = errors.New("websocket connection not found")
+)
+
+// Message frame exchanged with the clients, Type selects the endpoint of inbound messages
+// and ID correlates the reply with the request
+type Message struct {
+	Type string          `json:"type"`
+	ID   string          `json:"id,omitempty"`
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+// Conn client connection, outbound messages are queued and written by a single goroutine
+type Conn struct {
+	id         string
+	hub        *Hub
+	ws         *websocket.Conn
+	send       chan []byte
+	done       chan struct{}
+	closeOnce  sync.Once
+	groups     map[string]bool
+	dropOnFull bool
+}
+
+type outboundMessage struct {
+	Type string      `json:"type"`
+	ID   string      `json:"id,omitempty"`
+	Data interface{} `json:"data,omitempty"`
+}
+
+type contextKey string
+
+const connContextKey contextKey = "wstransport.conn"
+
+// ConnFromContext the connection of the message being handled
+func ConnFromContext(ctx context.Context) (*Conn, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+
+	c, ok := ctx.Value(connContextKey).(*Conn)
+	return c, ok
+}
+
+// ID unique id of the connection
+func (c *Conn) ID() string {
+	return c.id
+}
+
+// Send queues the message, when the buffer is full the message is dropped or the
+// connection is closed (slow consumer), see ServerDropOnFull
+func (c *Conn) Send(msgType string, data interface{}) error {
+	frame, err := encodeMessage(msgType, "", data)
+	if err != nil {
+		return err
+	}
+
+	return c.enqueue(frame)
+}
+
+// Join adds the connection to the group
+func (c *Conn) Join(group string) {
+	c.hub.Join(c, group)
+}
+
+// Leave removes the connection from the group
+func (c *Conn) Leave(group string) {
+	c.hub.Leave(c, group)
+}
+
+// Close closes the connection with the normal closure code
+func (c *Conn) Close() {
+	c.closeWith(websocket.CloseNormalClosure, "")
+}
+
+func (c *Conn) enqueue(frame []byte) error {
+	select {
+	case <-c.done:
+		return ErrConnClosed
+	default:
+	}
+
+	select {
+	case c.send <- frame:
+		return nil
+	default:
+	}
+
+	if c.dropOnFull {
+		logrus.WithField("wstransport.dropped", c.id).Debug(ErrSendBufferFull)
+		return ErrSendBufferFull
+	}
+
+	logrus.WithField("wstransport.slow", c.id).Warn(ErrSendBufferFull)
+	c.closeWith(websocket.ClosePolicyViolation, "slow consumer")
+	return ErrSendBufferFull
+}
+
+func (c *Conn) closeWith(code int, reason string) {
+	c.closeOnce.Do(func() {
+		c.ws.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(time.Second))
+		close(c.done)
+		c.ws.Close()
+	})
+}
+
+// terminate closes the broken connection without close frame
+func (c *Conn) terminate() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+		c.ws.Close()
+	})
+}
+
+// writeLoop writes the queued messages and the pings until the connection is closed
+func (c *Conn) writeLoop(pingInterval, writeTimeout time.Duration) {
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case frame := <-c.send:
+			c.ws.SetWriteDeadline(time.Now().Add(writeTimeout))
+			if err := c.ws.WriteMessage(websocket.TextMessage, frame); err != nil {
+				logrus.WithField("wstransport.write", c.id).Debug(err)
+				c.terminate()
+				return
+			}
+		case <-ticker.C:
+			if err := c.ws.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeTimeout)); err != nil {
+				logrus.WithField("wstransport.ping", c.id).Debug(err)
+				c.terminate()
+				return
+			}
+		}
+	}
+}
+
+func encodeMessage(msgType, id string, data interface{}) ([]byte, error) {
+	return json.Marshal(outboundMessage{Type: msgType, ID: id, Data: data})
+}
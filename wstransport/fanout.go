@@ -0,0 +1,56 @@
+package wstransport
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/helderfarias/go-api-kit/endpoint"
+	"github.com/nats-io/nats.go"
+	"github.com/sirupsen/logrus"
+)
+
+// FanOut endpoint broadcasting the messages received from the broker to the connections, to be
+// subscribed with mb.Subscriber.Subscribe. JSON payloads are sent as data, group selects the
+// group of the message. Without group the messages go to every connection, messages whose
+// group is "" are dropped.
+//
+//	sub.Delivery(sub.Subscribe("NOTIFICATIONS", "notifications.>", "ws", hub.FanOut("notification", func(msg *nats.Msg) string {
+//		return strings.TrimPrefix(msg.Subject, "notifications.")
+//	})))
+func (h *Hub) FanOut(msgType string, group func(msg *nats.Msg) string) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+		var data interface{}
+		target := ""
+
+		switch m := request.(type) {
+		case *nats.Msg:
+			data = payload(m.Data)
+			if group != nil {
+				if target = group(m); target == "" {
+					logrus.WithField("wstransport.fanout", m.Subject).Debug("message without group dropped")
+					return endpoint.Response(http.StatusOK, map[string]int{"sent": 0}), nil
+				}
+			}
+		case []byte:
+			data = payload(m)
+		default:
+			data = m
+		}
+
+		sent, err := h.Broadcast(target, msgType, data)
+		if err != nil {
+			return nil, err
+		}
+
+		return endpoint.Response(http.StatusOK, map[string]int{"sent": sent}), nil
+	}
+}
+
+// payload raw JSON is sent as is and other payloads as string
+func payload(data []byte) interface{} {
+	if json.Valid(data) {
+		return json.RawMessage(data)
+	}
+	return string(data)
+}
@@ -0,0 +1,132 @@
+package wstransport
+
+import (
+	"sync"
+)
+
+// Hub connected clients and their groups, used to push messages from handlers,
+// other requests or the message broker (see FanOut)
+type Hub struct {
+	mu     sync.RWMutex
+	conns  map[string]*Conn
+	groups map[string]map[string]*Conn
+}
+
+// NewHub constructs an empty hub
+func NewHub() *Hub {
+	return &Hub{
+		conns:  map[string]*Conn{},
+		groups: map[string]map[string]*Conn{},
+	}
+}
+
+// Conn the connection of the id
+func (h *Hub) Conn(id string) (*Conn, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	c, ok := h.conns[id]
+	return c, ok
+}
+
+// Len connected clients
+func (h *Hub) Len() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return len(h.conns)
+}
+
+// Join adds the connection to the group
+func (h *Hub) Join(c *Conn, group string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.conns[c.id]; !ok {
+		return
+	}
+
+	members, ok := h.groups[group]
+	if !ok {
+		members = map[string]*Conn{}
+		h.groups[group] = members
+	}
+
+	members[c.id] = c
+	c.groups[group] = true
+}
+
+// Leave removes the connection from the group
+func (h *Hub) Leave(c *Conn, group string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.leave(c, group)
+}
+
+// Send pushes the message to the connection of the id
+func (h *Hub) Send(id, msgType string, data interface{}) error {
+	c, ok := h.Conn(id)
+	if !ok {
+		return ErrConnNotFound
+	}
+
+	return c.Send(msgType, data)
+}
+
+// Broadcast pushes the message to the members of the group, to every connection when the
+// group is empty. Returns the number of connections the message was queued to.
+func (h *Hub) Broadcast(group, msgType string, data interface{}) (int, error) {
+	frame, err := encodeMessage(msgType, "", data)
+	if err != nil {
+		return 0, err
+	}
+
+	h.mu.RLock()
+	targets := []*Conn{}
+	members := h.conns
+	if group != "" {
+		members = h.groups[group]
+	}
+	for _, c := range members {
+		targets = append(targets, c)
+	}
+	h.mu.RUnlock()
+
+	sent := 0
+	for _, c := range targets {
+		if c.enqueue(frame) == nil {
+			sent++
+		}
+	}
+
+	return sent, nil
+}
+
+func (h *Hub) register(c *Conn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.conns[c.id] = c
+}
+
+func (h *Hub) unregister(c *Conn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for group := range c.groups {
+		h.leave(c, group)
+	}
+	delete(h.conns, c.id)
+}
+
+func (h *Hub) leave(c *Conn, group string) {
+	delete(c.groups, group)
+
+	if members, ok := h.groups[group]; ok {
+		delete(members, c.id)
+		if len(members) == 0 {
+			delete(h.groups, group)
+		}
+	}
+}
@@ -0,0 +1,292 @@
+// Package wstransport WebSocket transport dispatching inbound messages to endpoints
+// and pushing outbound messages to connections and groups
+package wstransport
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/helderfarias/go-api-kit/apierror"
+	"github.com/helderfarias/go-api-kit/endpoint"
+	"github.com/helderfarias/go-api-kit/httptransport"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrorType type of the messages replying to failed inbound messages
+const ErrorType = "error"
+
+// DecodeRequestFunc extracts the endpoint request from the inbound message
+type DecodeRequestFunc func(ctx context.Context, msg Message) (interface{}, error)
+
+// EndpointCodec endpoint of a message type, nil Decode passes the Message as request
+type EndpointCodec struct {
+	Endpoint endpoint.Endpoint
+	Decode   DecodeRequestFunc
+}
+
+// EndpointCodecMap endpoints by message type
+type EndpointCodecMap map[string]EndpointCodec
+
+// ConnectFunc runs after the upgrade, e.g. to join the groups of the user, errors close the connection
+type ConnectFunc func(ctx context.Context, c *Conn) error
+
+// DisconnectFunc runs after the connection is closed
+type DisconnectFunc func(ctx context.Context, c *Conn)
+
+// Server upgrades the HTTP connections and dispatches the inbound messages, implements http.Handler
+type Server struct {
+	hub          *Hub
+	ecm          EndpointCodecMap
+	upgrader     websocket.Upgrader
+	before       []httptransport.RequestFunc
+	onConnect    []ConnectFunc
+	onDisconnect []DisconnectFunc
+	pingInterval time.Duration
+	writeTimeout time.Duration
+	sendBuffer   int
+	readLimit    int64
+	dropOnFull   bool
+}
+
+// ServerOption sets an optional parameter for servers
+type ServerOption func(s *Server)
+
+// NewServer constructs a server registering the connections in the hub
+func NewServer(hub *Hub, ecm EndpointCodecMap, options ...ServerOption) *Server {
+	s := &Server{
+		hub:          hub,
+		ecm:          ecm,
+		pingInterval: 30 * time.Second,
+		writeTimeout: 10 * time.Second,
+		sendBuffer:   256,
+		readLimit:    64 * 1024,
+	}
+
+	for _, o := range options {
+		o(s)
+	}
+
+	return s
+}
+
+// ServerBefore functions executed on the upgrade request, e.g. httptransport.PopulateAuthToken,
+// the context is shared by every message of the connection
+func ServerBefore(before ...httptransport.RequestFunc) ServerOption {
+	return func(s *Server) {
+		s.before = append(s.before, before...)
+	}
+}
+
+// ServerOnConnect functions executed after the connection is registered
+func ServerOnConnect(onConnect ...ConnectFunc) ServerOption {
+	return func(s *Server) {
+		s.onConnect = append(s.onConnect, onConnect...)
+	}
+}
+
+// ServerOnDisconnect functions executed after the connection is closed
+func ServerOnDisconnect(onDisconnect ...DisconnectFunc) ServerOption {
+	return func(s *Server) {
+		s.onDisconnect = append(s.onDisconnect, onDisconnect...)
+	}
+}
+
+// ServerCheckOrigin validates the Origin header, by default only same origin requests are upgraded
+func ServerCheckOrigin(checkOrigin func(r *http.Request) bool) ServerOption {
+	return func(s *Server) {
+		s.upgrader.CheckOrigin = checkOrigin
+	}
+}
+
+// ServerPingInterval interval of the pings, connections without pong for two intervals are closed
+func ServerPingInterval(interval time.Duration) ServerOption {
+	return func(s *Server) {
+		s.pingInterval = interval
+	}
+}
+
+// ServerSendBuffer outbound messages queued per connection
+func ServerSendBuffer(size int) ServerOption {
+	return func(s *Server) {
+		s.sendBuffer = size
+	}
+}
+
+// ServerReadLimit maximum size of inbound messages
+func ServerReadLimit(limit int64) ServerOption {
+	return func(s *Server) {
+		s.readLimit = limit
+	}
+}
+
+// ServerDropOnFull drops messages to slow consumers instead of closing their connections
+func ServerDropOnFull() ServerOption {
+	return func(s *Server) {
+		s.dropOnFull = true
+	}
+}
+
+// DecodeData decodes the message data into the value returned by newRequest
+func DecodeData(newRequest func() interface{}) DecodeRequestFunc {
+	return func(ctx context.Context, msg Message) (interface{}, error) {
+		request := newRequest()
+
+		if len(msg.Data) == 0 {
+			return request, nil
+		}
+
+		if err := json.Unmarshal(msg.Data, request); err != nil {
+			return nil, apierror.Wrap(err, http.StatusBadRequest, "bad_request", "invalid message data")
+		}
+
+		return request, nil
+	}
+}
+
+// ServeHTTP implements http.Handler, messages of a connection are handled in order and
+// the replies (EndpointResponse.Data) are sent with the type and id of the inbound message
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	for _, f := range s.before {
+		ctx = f(ctx, r)
+	}
+
+	ws, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		logrus.WithField("wstransport.upgrade", r.URL.Path).Debug(err)
+		return
+	}
+
+	c := &Conn{
+		id:         newConnID(),
+		hub:        s.hub,
+		ws:         ws,
+		send:       make(chan []byte, s.sendBuffer),
+		done:       make(chan struct{}),
+		groups:     map[string]bool{},
+		dropOnFull: s.dropOnFull,
+	}
+
+	s.hub.register(c)
+	defer func() {
+		s.hub.unregister(c)
+		c.closeWith(websocket.CloseNormalClosure, "")
+
+		for _, f := range s.onDisconnect {
+			f(ctx, c)
+		}
+	}()
+
+	ctx = context.WithValue(ctx, connContextKey, c)
+
+	go c.writeLoop(s.pingInterval, s.writeTimeout)
+
+	for _, f := range s.onConnect {
+		if err := f(ctx, c); err != nil {
+			logrus.WithField("wstransport.connect", c.id).Debug(err)
+			c.closeWith(websocket.ClosePolicyViolation, "connection rejected")
+			return
+		}
+	}
+
+	s.readLoop(ctx, c)
+}
+
+func (s *Server) readLoop(ctx context.Context, c *Conn) {
+	pongWait := 2 * s.pingInterval
+
+	c.ws.SetReadLimit(s.readLimit)
+	c.ws.SetReadDeadline(time.Now().Add(pongWait))
+	c.ws.SetPongHandler(func(string) error {
+		return c.ws.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		_, frame, err := c.ws.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				logrus.WithField("wstransport.read", c.id).Debug(err)
+			}
+			return
+		}
+
+		c.ws.SetReadDeadline(time.Now().Add(pongWait))
+
+		msg := Message{}
+		if err := json.Unmarshal(frame, &msg); err != nil || msg.Type == "" {
+			s.reply(c, ErrorType, msg.ID, errorData(apierror.BadRequest("invalid message")))
+			continue
+		}
+
+		s.dispatch(ctx, c, msg)
+	}
+}
+
+func (s *Server) dispatch(ctx context.Context, c *Conn, msg Message) {
+	ec, ok := s.ecm[msg.Type]
+	if !ok {
+		s.reply(c, ErrorType, msg.ID, errorData(apierror.NotFound("unknown message type "+msg.Type)))
+		return
+	}
+
+	var request interface{} = msg
+	if ec.Decode != nil {
+		r, err := ec.Decode(ctx, msg)
+		if err != nil {
+			s.reply(c, ErrorType, msg.ID, errorData(err))
+			return
+		}
+		request = r
+	}
+
+	resp, err := ec.Endpoint(ctx, request)
+	if err != nil {
+		logrus.WithField("wstransport.endpoint", msg.Type).Debug(err)
+		s.reply(c, ErrorType, msg.ID, errorData(err))
+		return
+	}
+
+	if resp == nil || resp.Data() == nil {
+		return
+	}
+
+	if resp.Code() >= http.StatusInternalServerError {
+		logrus.WithField("wstransport.response", resp.Code()).Error(resp.Data())
+		s.reply(c, ErrorType, msg.ID, map[string]interface{}{"status": resp.Code(), "error": map[string]string{"message": http.StatusText(resp.Code())}})
+		return
+	}
+
+	if resp.Code() >= http.StatusBadRequest {
+		s.reply(c, ErrorType, msg.ID, map[string]interface{}{"status": resp.Code(), "error": resp.Data()})
+		return
+	}
+
+	s.reply(c, msg.Type, msg.ID, resp.Data())
+}
+
+func (s *Server) reply(c *Conn, msgType, id string, data interface{}) {
+	frame, err := encodeMessage(msgType, id, data)
+	if err != nil {
+		logrus.Error(err)
+		return
+	}
+
+	c.enqueue(frame)
+}
+
+// errorData error frame with the status and message of apierror.Public
+func errorData(err error) map[string]interface{} {
+	e := apierror.Public(err)
+	return map[string]interface{}{"status": e.StatusCode(), "error": map[string]string{"message": e.Message}}
+}
+
+func newConnID() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,200 @@
+package wstransport
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/helderfarias/go-api-kit/apierror"
+	"github.com/helderfarias/go-api-kit/endpoint"
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+)
+
+type joinRequest struct {
+	Room string `json:"room"`
+}
+
+func newTestServer(hub *Hub, options ...ServerOption) *httptest.Server {
+	return httptest.NewServer(NewServer(hub, EndpointCodecMap{
+		"join": {
+			Endpoint: func(ctx context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+				c, _ := ConnFromContext(ctx)
+				c.Join(request.(*joinRequest).Room)
+				return endpoint.Response(200, map[string]string{"joined": request.(*joinRequest).Room}), nil
+			},
+			Decode: DecodeData(func() interface{} { return &joinRequest{} }),
+		},
+		"forbidden": {
+			Endpoint: func(ctx context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+				return nil, apierror.Forbidden("not allowed")
+			},
+		},
+		"unavailable": {
+			Endpoint: func(ctx context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+				return endpoint.Response(503, map[string]string{"message": "redis: dial tcp 10.0.0.5:6379"}), nil
+			},
+		},
+		"fail": {
+			Endpoint: func(ctx context.Context, request interface{}) (endpoint.EndpointResponse, error) {
+				return nil, errors.New("pq: connection refused")
+			},
+		},
+	}, options...))
+}
+
+func connect(t *testing.T, server *httptest.Server) *websocket.Conn {
+	ws, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	assert.Nil(t, err)
+	return ws
+}
+
+func read(t *testing.T, ws *websocket.Conn) string {
+	ws.SetReadDeadline(time.Now().Add(time.Second))
+	_, frame, err := ws.ReadMessage()
+	assert.Nil(t, err)
+	return string(frame)
+}
+
+func waitFor(cond func() bool) {
+	for i := 0; i < 100 && !cond(); i++ {
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestServerDispatchesByTypeAndPushesToGroups(t *testing.T) {
+	hub := NewHub()
+	server := newTestServer(hub)
+	defer server.Close()
+
+	alice, bob := connect(t, server), connect(t, server)
+	defer alice.Close()
+	defer bob.Close()
+
+	alice.WriteJSON(map[string]interface{}{"type": "join", "id": "1", "data": map[string]string{"room": "news"}})
+	assert.JSONEq(t, `{"type":"join","id":"1","data":{"joined":"news"}}`, read(t, alice))
+
+	bob.WriteJSON(map[string]interface{}{"type": "forbidden", "id": "2"})
+	assert.JSONEq(t, `{"type":"error","id":"2","data":{"status":403,"error":{"message":"not allowed"}}}`, read(t, bob))
+
+	bob.WriteJSON(map[string]interface{}{"type": "fail", "id": "3"})
+	assert.JSONEq(t, `{"type":"error","id":"3","data":{"status":500,"error":{"message":"Internal Server Error"}}}`, read(t, bob))
+
+	bob.WriteJSON(map[string]interface{}{"type": "unavailable", "id": "4"})
+	assert.JSONEq(t, `{"type":"error","id":"4","data":{"status":503,"error":{"message":"Service Unavailable"}}}`, read(t, bob))
+
+	bob.WriteJSON(map[string]interface{}{"type": "unknown"})
+	assert.JSONEq(t, `{"type":"error","data":{"status":404,"error":{"message":"unknown message type unknown"}}}`, read(t, bob))
+
+	sent, err := hub.Broadcast("news", "headline", map[string]string{"title": "Hello"})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, sent)
+	assert.JSONEq(t, `{"type":"headline","data":{"title":"Hello"}}`, read(t, alice))
+
+	sent, _ = hub.Broadcast("", "maintenance", "soon")
+	assert.Equal(t, 2, sent)
+	assert.JSONEq(t, `{"type":"maintenance","data":"soon"}`, read(t, alice))
+	assert.JSONEq(t, `{"type":"maintenance","data":"soon"}`, read(t, bob))
+
+	bob.Close()
+	waitFor(func() bool { return hub.Len() == 1 })
+	assert.Equal(t, 1, hub.Len())
+}
+
+func TestServerPingKeepaliveAndConnectHooks(t *testing.T) {
+	hub := NewHub()
+	disconnected := make(chan string, 1)
+
+	server := newTestServer(hub,
+		ServerPingInterval(20*time.Millisecond),
+		ServerOnConnect(func(ctx context.Context, c *Conn) error {
+			c.Join("everyone")
+			return c.Send("welcome", c.ID())
+		}),
+		ServerOnDisconnect(func(ctx context.Context, c *Conn) {
+			disconnected <- c.ID()
+		}),
+	)
+	defer server.Close()
+
+	ws := connect(t, server)
+
+	pings := make(chan bool, 10)
+	ws.SetPingHandler(func(data string) error {
+		pings <- true
+		return ws.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(time.Second))
+	})
+
+	welcome := read(t, ws)
+	assert.Contains(t, welcome, `"type":"welcome"`)
+
+	go ws.ReadMessage()
+	select {
+	case <-pings:
+	case <-time.After(time.Second):
+		t.Fatal("no ping received")
+	}
+
+	ws.Close()
+	select {
+	case id := <-disconnected:
+		assert.Contains(t, welcome, id)
+	case <-time.After(time.Second):
+		t.Fatal("disconnect not called")
+	}
+}
+
+func TestServerRejectedConnectionGetsAFixedCloseReason(t *testing.T) {
+	server := newTestServer(NewHub(), ServerOnConnect(func(ctx context.Context, c *Conn) error {
+		return errors.New("pq: password authentication failed for user admin")
+	}))
+	defer server.Close()
+
+	ws := connect(t, server)
+	defer ws.Close()
+
+	ws.SetReadDeadline(time.Now().Add(time.Second))
+	_, _, err := ws.ReadMessage()
+
+	closeErr, ok := err.(*websocket.CloseError)
+	assert.True(t, ok)
+	assert.Equal(t, websocket.ClosePolicyViolation, closeErr.Code)
+	assert.Equal(t, "connection rejected", closeErr.Text)
+}
+
+func TestHubSlowConsumerAndFanOut(t *testing.T) {
+	hub := NewHub()
+	server := newTestServer(hub, ServerSendBuffer(1), ServerOnConnect(func(ctx context.Context, c *Conn) error {
+		c.Join("user.42")
+		return nil
+	}))
+	defer server.Close()
+
+	ws := connect(t, server)
+	defer ws.Close()
+	waitFor(func() bool { return hub.Len() == 1 })
+
+	fanOut := hub.FanOut("notification", func(msg *nats.Msg) string {
+		return strings.TrimPrefix(msg.Subject, "notifications.")
+	})
+
+	resp, err := fanOut(nil, &nats.Msg{Subject: "notifications.user.42", Data: []byte(`{"text":"hi"}`)})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]int{"sent": 1}, resp.Data())
+	assert.JSONEq(t, `{"type":"notification","data":{"text":"hi"}}`, read(t, ws))
+
+	resp, err = fanOut(nil, &nats.Msg{Subject: "notifications.", Data: []byte(`{"text":"all"}`)})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]int{"sent": 0}, resp.Data())
+
+	// the client stops reading, the buffer fills and the connection is closed
+	for i := 0; i < 1000 && hub.Len() > 0; i++ {
+		hub.Broadcast("", "flood", strings.Repeat("x", 1024))
+	}
+	waitFor(func() bool { return hub.Len() == 0 })
+	assert.Equal(t, 0, hub.Len())
+}